@@ -14,8 +14,8 @@ func main() {
 	// 设置中文字体
 	setChineseFont()
 
-	// 创建应用实例
-	application := app.New()
+	// 创建应用实例，偏好设置（例如最近打开的项目）按应用ID保存
+	application := app.NewWithID("com.lowsqlgen.app")
 
 	// 设置自定义主题
 	application.Settings().SetTheme(gui.NewMyTheme())
@@ -1,63 +1,170 @@
 package gui
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	fynedialog "fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/lowSqlGen/internal/model"
+	"github.com/lowSqlGen/internal/service"
 )
 
+// drivers 可选的数据库驱动，按界面中的显示顺序排列
+var drivers = []string{model.DriverMySQL, model.DriverPostgres, model.DriverSQLite}
+
+// driverNames 数据库驱动在界面中显示的名称
+var driverNames = map[string]string{
+	model.DriverMySQL:    "MySQL",
+	model.DriverPostgres: "PostgreSQL",
+	model.DriverSQLite:   "SQLite",
+}
+
+// defaultPorts 各数据库的默认端口
+var defaultPorts = map[string]string{
+	model.DriverMySQL:    "3306",
+	model.DriverPostgres: "5432",
+}
+
+// tlsModes 可选的加密方式及显示名称，按界面中的显示顺序排列
+var tlsModes = []string{model.TLSDisabled, model.TLSRequire, model.TLSVerify}
+
+var tlsModeNames = map[string]string{
+	model.TLSDisabled: "Disabled",
+	model.TLSRequire:  "Required",
+	model.TLSVerify:   "Verify certificate",
+}
+
 type DBConfigDialog struct {
 	window   fyne.Window
-	config   *model.DatabaseConfig
+	profiles *service.ProfileStore // 为 nil 时不提供保存的连接配置
 	onSubmit func(config *model.DatabaseConfig)
+
+	profileSelect *widget.Select
+	driverSelect  *widget.Select
+	hostEntry     *widget.Entry
+	portEntry     *widget.Entry
+	usernameEntry *widget.Entry
+	passwordEntry *widget.Entry
+	databaseEntry *widget.Entry
+	fileEntry     *widget.Entry
+	charsetEntry  *widget.Entry
+	timeoutEntry  *widget.Entry
+	tlsSelect     *widget.Select
+	caEntry       *widget.Entry
+	certEntry     *widget.Entry
+	keyEntry      *widget.Entry
+	saveCheck     *widget.Check
+	nameEntry     *widget.Entry
 }
 
-func NewDBConfigDialog(parent fyne.Window) *DBConfigDialog {
+func NewDBConfigDialog(parent fyne.Window, profiles *service.ProfileStore) *DBConfigDialog {
 	dialog := &DBConfigDialog{
-		window: fyne.CurrentApp().NewWindow("Database Connection Configuration"),
-		config: &model.DatabaseConfig{},
+		window:        fyne.CurrentApp().NewWindow("Database Connection Configuration"),
+		profiles:      profiles,
+		hostEntry:     widget.NewEntry(),
+		portEntry:     widget.NewEntry(),
+		usernameEntry: widget.NewEntry(),
+		passwordEntry: widget.NewPasswordEntry(),
+		databaseEntry: widget.NewEntry(),
+		fileEntry:     widget.NewEntry(),
+		charsetEntry:  widget.NewEntry(),
+		timeoutEntry:  widget.NewEntry(),
+		caEntry:       widget.NewEntry(),
+		certEntry:     widget.NewEntry(),
+		keyEntry:      widget.NewEntry(),
+		nameEntry:     widget.NewEntry(),
 	}
 
 	// 创建输入框
-	hostEntry := widget.NewEntry()
-	hostEntry.SetText("127.0.0.1")
+	dialog.hostEntry.SetText("127.0.0.1")
+	dialog.portEntry.SetText(defaultPorts[model.DriverMySQL])
+	dialog.usernameEntry.SetPlaceHolder("root")
+	// PostgreSQL 需要连接到一个具体的数据库，MySQL 可以留空
+	dialog.databaseEntry.SetPlaceHolder("postgres")
+	dialog.fileEntry.SetPlaceHolder("path/to/schema.db")
+	dialog.charsetEntry.SetPlaceHolder("driver default")
+	dialog.timeoutEntry.SetPlaceHolder("seconds")
+	dialog.nameEntry.SetPlaceHolder("profile name")
 
-	portEntry := widget.NewEntry()
-	portEntry.SetText("3306")
+	// SQLite 数据库文件
+	fileRow, browseBtn := dialog.fileRow(dialog.fileEntry)
+	caRow, caBtn := dialog.fileRow(dialog.caEntry)
+	certRow, certBtn := dialog.fileRow(dialog.certEntry)
+	keyRow, keyBtn := dialog.fileRow(dialog.keyEntry)
+	certEntries := []fyne.Disableable{dialog.caEntry, caBtn, dialog.certEntry, certBtn, dialog.keyEntry, keyBtn}
+
+	// 加密方式，不加密时证书设置不可用
+	var tlsOptions []string
+	for _, mode := range tlsModes {
+		tlsOptions = append(tlsOptions, tlsModeNames[mode])
+	}
+	dialog.tlsSelect = widget.NewSelect(tlsOptions, func(string) {
+		setEnabled(certEntries, dialog.tlsSelect.SelectedIndex() > 0 && dialog.selectedDriver() != model.DriverSQLite)
+	})
 
-	usernameEntry := widget.NewEntry()
-	usernameEntry.SetText("root")
+	networkEntries := []fyne.Disableable{
+		dialog.hostEntry, dialog.portEntry, dialog.usernameEntry, dialog.passwordEntry, dialog.databaseEntry,
+		dialog.charsetEntry, dialog.timeoutEntry, dialog.tlsSelect,
+	}
+
+	// 数据库类型，切换时更新默认端口，SQLite 只需要选择文件
+	var driverOptions []string
+	for _, driver := range drivers {
+		driverOptions = append(driverOptions, driverNames[driver])
+	}
+	dialog.driverSelect = widget.NewSelect(driverOptions, nil)
+	dialog.driverSelect.OnChanged = func(selected string) {
+		driver := dialog.selectedDriver()
+		if port, ok := defaultPorts[driver]; ok {
+			for _, other := range defaultPorts {
+				if dialog.portEntry.Text == other {
+					dialog.portEntry.SetText(port)
+					break
+				}
+			}
+		}
+		setEnabled(networkEntries, driver != model.DriverSQLite)
+		setEnabled(certEntries, driver != model.DriverSQLite && dialog.tlsSelect.SelectedIndex() > 0)
+		setEnabled([]fyne.Disableable{dialog.fileEntry, browseBtn}, driver == model.DriverSQLite)
+	}
+	dialog.driverSelect.SetSelected(driverNames[model.DriverMySQL])
+	dialog.tlsSelect.SetSelected(tlsModeNames[model.TLSDisabled])
 
-	passwordEntry := widget.NewPasswordEntry()
-	passwordEntry.SetText("root")
 	// 创建表单
 	form := &widget.Form{
 		Items: []*widget.FormItem{
-			{Text: "Host", Widget: hostEntry},
-			{Text: "Port", Widget: portEntry},
-			{Text: "Username", Widget: usernameEntry},
-			{Text: "Password", Widget: passwordEntry},
-		},
-		OnSubmit: func() {
-			dialog.config.Host = hostEntry.Text
-			dialog.config.Port = portEntry.Text
-			dialog.config.Username = usernameEntry.Text
-			dialog.config.Password = passwordEntry.Text
-
-			if dialog.onSubmit != nil {
-				dialog.onSubmit(dialog.config)
-			}
-			dialog.window.Close()
+			{Text: "Type", Widget: dialog.driverSelect},
+			{Text: "Host", Widget: dialog.hostEntry},
+			{Text: "Port", Widget: dialog.portEntry},
+			{Text: "Username", Widget: dialog.usernameEntry},
+			{Text: "Password", Widget: dialog.passwordEntry},
+			{Text: "Database", Widget: dialog.databaseEntry, HintText: "Default database"},
+			{Text: "File", Widget: fileRow},
+			{Text: "Charset", Widget: dialog.charsetEntry},
+			{Text: "Timeout", Widget: dialog.timeoutEntry},
+			{Text: "TLS", Widget: dialog.tlsSelect},
+			{Text: "CA file", Widget: caRow},
+			{Text: "Client cert", Widget: certRow},
+			{Text: "Client key", Widget: keyRow},
 		},
+		OnSubmit: dialog.submit,
 		OnCancel: func() {
 			dialog.window.Close()
 		},
 	}
 
+	content := fyne.CanvasObject(form)
+	if profiles != nil {
+		content = container.NewVBox(dialog.profileBar(), widget.NewSeparator(), form)
+	}
+
 	// 设置窗口内容
-	dialog.window.SetContent(container.NewPadded(form))
-	dialog.window.Resize(fyne.NewSize(300, 250))
+	dialog.window.SetContent(container.NewVScroll(container.NewPadded(content)))
+	dialog.window.Resize(fyne.NewSize(440, 640))
 	dialog.window.CenterOnScreen()
 
 	return dialog
@@ -70,3 +177,192 @@ func (d *DBConfigDialog) Show() {
 func (d *DBConfigDialog) SetOnSubmit(callback func(config *model.DatabaseConfig)) {
 	d.onSubmit = callback
 }
+
+// profileBar 选择、删除和保存连接配置的控件
+func (d *DBConfigDialog) profileBar() fyne.CanvasObject {
+	d.profileSelect = widget.NewSelect(d.profiles.Names(), nil)
+	d.profileSelect.PlaceHolder = "(new connection)"
+	d.profileSelect.OnChanged = d.pickProfile
+
+	deleteBtn := widget.NewButton("Delete", func() {
+		name := d.profileSelect.Selected
+		if name == "" {
+			return
+		}
+		fynedialog.ShowConfirm("Delete profile", fmt.Sprintf("Delete saved connection %q?", name), func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := d.profiles.Delete(name); err != nil {
+				fynedialog.ShowError(err, d.window)
+				return
+			}
+			d.profileSelect.Options = d.profiles.Names()
+			d.profileSelect.ClearSelected()
+			d.nameEntry.SetText("")
+		}, d.window)
+	})
+
+	d.saveCheck = widget.NewCheck("Save as profile", func(checked bool) {
+		if checked {
+			d.nameEntry.Enable()
+		} else {
+			d.nameEntry.Disable()
+		}
+	})
+	d.nameEntry.Disable()
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Profile"), deleteBtn, d.profileSelect),
+		container.NewBorder(nil, nil, d.saveCheck, nil, d.nameEntry),
+	)
+}
+
+// pickProfile 用连接配置填写表单，配置中保存了密码时先询问主密码
+func (d *DBConfigDialog) pickProfile(name string) {
+	if name == "" {
+		return
+	}
+	if d.profiles.NeedsUnlock(name) {
+		ShowPassphraseDialog(d.window, d.profiles, func() {
+			d.pickProfile(name)
+		}, func() {
+			d.profileSelect.ClearSelected()
+		})
+		return
+	}
+	config, err := d.profiles.Config(name)
+	if err != nil {
+		fynedialog.ShowError(err, d.window)
+		return
+	}
+	d.fill(config)
+	d.nameEntry.SetText(name)
+}
+
+// fill 用连接配置填写表单
+func (d *DBConfigDialog) fill(config *model.DatabaseConfig) {
+	driver := config.Driver
+	if driver == "" {
+		driver = model.DriverMySQL
+	}
+	d.driverSelect.SetSelected(driverNames[driver])
+	d.hostEntry.SetText(config.Host)
+	d.portEntry.SetText(config.Port)
+	d.usernameEntry.SetText(config.Username)
+	d.passwordEntry.SetText(config.Password)
+	d.databaseEntry.SetText(config.Database)
+	d.fileEntry.SetText(config.FilePath)
+	d.charsetEntry.SetText(config.Charset)
+	d.timeoutEntry.SetText("")
+	if config.Timeout > 0 {
+		d.timeoutEntry.SetText(strconv.Itoa(config.Timeout))
+	}
+	d.tlsSelect.SetSelected(tlsModeNames[config.TLS.Mode])
+	d.caEntry.SetText(config.TLS.CAFile)
+	d.certEntry.SetText(config.TLS.CertFile)
+	d.keyEntry.SetText(config.TLS.KeyFile)
+}
+
+// config 读取表单中的连接配置
+func (d *DBConfigDialog) config() (*model.DatabaseConfig, error) {
+	timeout := 0
+	if text := strings.TrimSpace(d.timeoutEntry.Text); text != "" {
+		value, err := strconv.Atoi(text)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("Timeout must be a number of seconds")
+		}
+		timeout = value
+	}
+	return &model.DatabaseConfig{
+		Driver:   d.selectedDriver(),
+		Host:     d.hostEntry.Text,
+		Port:     d.portEntry.Text,
+		Username: d.usernameEntry.Text,
+		Password: d.passwordEntry.Text,
+		Database: d.databaseEntry.Text,
+		FilePath: d.fileEntry.Text,
+		Charset:  strings.TrimSpace(d.charsetEntry.Text),
+		Timeout:  timeout,
+		TLS: model.TLSOptions{
+			Mode:     tlsModes[d.tlsSelect.SelectedIndex()],
+			CAFile:   d.caEntry.Text,
+			CertFile: d.certEntry.Text,
+			KeyFile:  d.keyEntry.Text,
+		},
+	}, nil
+}
+
+// submit 按需保存连接配置，然后连接
+func (d *DBConfigDialog) submit() {
+	config, err := d.config()
+	if err != nil {
+		fynedialog.ShowError(err, d.window)
+		return
+	}
+
+	if d.profiles != nil && d.saveCheck.Checked {
+		name := strings.TrimSpace(d.nameEntry.Text)
+		if name == "" {
+			fynedialog.ShowError(fmt.Errorf("Please enter a profile name"), d.window)
+			return
+		}
+		// 保存密码需要主密码
+		if config.Password != "" && !d.profiles.Unlocked() {
+			ShowPassphraseDialog(d.window, d.profiles, d.submit, nil)
+			return
+		}
+		if err := d.profiles.Save(name, config); err != nil {
+			fynedialog.ShowError(err, d.window)
+			return
+		}
+	} else if name := d.selectedProfile(); name != "" {
+		// 未修改所选配置时记录配置名称，项目文件据此找到连接
+		if saved, err := d.profiles.Config(name); err == nil && service.NewConnectionRef(saved).Matches(config) && saved.Password == config.Password {
+			config.Profile = name
+		}
+	}
+
+	if d.onSubmit != nil {
+		d.onSubmit(config)
+	}
+	d.window.Close()
+}
+
+func (d *DBConfigDialog) selectedDriver() string {
+	if d.driverSelect.SelectedIndex() < 0 {
+		return model.DriverMySQL
+	}
+	return drivers[d.driverSelect.SelectedIndex()]
+}
+
+func (d *DBConfigDialog) selectedProfile() string {
+	if d.profileSelect == nil {
+		return ""
+	}
+	return d.profileSelect.Selected
+}
+
+// fileRow 文件路径输入框和选择文件的按钮
+func (d *DBConfigDialog) fileRow(entry *widget.Entry) (fyne.CanvasObject, *widget.Button) {
+	browseBtn := widget.NewButton("Browse", func() {
+		fynedialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			entry.SetText(reader.URI().Path())
+			reader.Close()
+		}, d.window)
+	})
+	return container.NewBorder(nil, nil, nil, browseBtn, entry), browseBtn
+}
+
+func setEnabled(widgets []fyne.Disableable, enabled bool) {
+	for _, w := range widgets {
+		if enabled {
+			w.Enable()
+		} else {
+			w.Disable()
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowDefinitionDialog 以只读方式显示对象的创建语句，可以复制到剪贴板
+func ShowDefinitionDialog(window fyne.Window, title, definition string) {
+	text := widget.NewLabelWithStyle(definition, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewScroll(text)
+	scroll.SetMinSize(fyne.NewSize(640, 400))
+
+	copyBtn := widget.NewButton("Copy", func() {
+		window.Clipboard().SetContent(definition)
+	})
+
+	d := dialog.NewCustom(title, "Close", container.NewBorder(nil, container.NewHBox(copyBtn), nil, nil, scroll), window)
+	d.Resize(fyne.NewSize(720, 520))
+	d.Show()
+}
@@ -0,0 +1,129 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// ExplainView 以树显示执行计划，全表扫描和缺少索引的步骤以醒目的颜色标出
+type ExplainView struct {
+	container *fyne.Container
+	tree      *widget.Tree
+	status    *widget.Label
+	steps     map[string]*service.PlanNode // 树节点ID -> 步骤，根步骤的ID为 "0"，子步骤为 "0/1" 这样的路径
+}
+
+func NewExplainView() *ExplainView {
+	v := &ExplainView{
+		status: widget.NewLabel("Explain a query to see its execution plan"),
+		steps:  make(map[string]*service.PlanNode),
+	}
+	v.status.Wrapping = fyne.TextWrapWord
+
+	v.tree = widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			if id == "" {
+				if _, ok := v.steps["0"]; ok {
+					return []widget.TreeNodeID{"0"}
+				}
+				return nil
+			}
+			var children []widget.TreeNodeID
+			if step, ok := v.steps[id]; ok {
+				for i := range step.Children {
+					children = append(children, fmt.Sprintf("%s/%d", id, i))
+				}
+			}
+			return children
+		},
+		func(id widget.TreeNodeID) bool {
+			step, ok := v.steps[id]
+			return id == "" || ok && len(step.Children) > 0
+		},
+		func(bool) fyne.CanvasObject {
+			return &widget.Label{Truncation: fyne.TextTruncateEllipsis}
+		},
+		func(id widget.TreeNodeID, _ bool, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			step, ok := v.steps[id]
+			if !ok {
+				label.SetText("")
+				return
+			}
+			label.Importance = widget.MediumImportance
+			if step.NoIndex {
+				label.Importance = widget.DangerImportance
+			} else if step.FullScan {
+				label.Importance = widget.WarningImportance
+			}
+			label.SetText(stepText(step))
+		},
+	)
+
+	v.container = container.NewBorder(v.status, nil, nil, nil, v.tree)
+	return v
+}
+
+func (v *ExplainView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// SetRunning 开始获取执行计划
+func (v *ExplainView) SetRunning() {
+	v.setStatus("Explaining...", widget.LowImportance)
+}
+
+// SetPlan 显示执行计划并展开所有步骤，err 不为 nil 时只显示错误
+func (v *ExplainView) SetPlan(plan *service.PlanNode, err error) {
+	if err != nil {
+		v.setStatus(err.Error(), widget.DangerImportance)
+		return
+	}
+
+	v.steps = make(map[string]*service.PlanNode)
+	fullScans, noIndex := 0, 0
+	var add func(id string, step *service.PlanNode)
+	add = func(id string, step *service.PlanNode) {
+		v.steps[id] = step
+		if step.NoIndex {
+			noIndex++
+		} else if step.FullScan {
+			fullScans++
+		}
+		for i, child := range step.Children {
+			add(fmt.Sprintf("%s/%d", id, i), child)
+		}
+	}
+	add("0", plan)
+
+	switch {
+	case noIndex > 0:
+		v.setStatus(fmt.Sprintf("%d step(s) without a usable index, %d other full table scan(s)", noIndex, fullScans), widget.DangerImportance)
+	case fullScans > 0:
+		v.setStatus(fmt.Sprintf("%d full table scan(s)", fullScans), widget.WarningImportance)
+	default:
+		v.setStatus("No full table scans", widget.SuccessImportance)
+	}
+	v.tree.Refresh()
+	v.tree.OpenAllBranches()
+}
+
+func (v *ExplainView) setStatus(text string, importance widget.Importance) {
+	v.status.Importance = importance
+	v.status.SetText(text)
+}
+
+// stepText 步骤的说明、问题和细节
+func stepText(step *service.PlanNode) string {
+	parts := []string{step.Label}
+	if warning := step.Warning(); warning != "" {
+		parts[0] += " - " + strings.ToUpper(warning)
+	}
+	parts = append(parts, step.Details...)
+	return strings.Join(parts, "  |  ")
+}
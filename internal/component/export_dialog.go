@@ -0,0 +1,126 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// csvDelimiters 可选的 CSV 分隔符
+var csvDelimiters = []struct {
+	name  string
+	value rune
+}{
+	{"Comma (,)", ','},
+	{"Semicolon (;)", ';'},
+	{"Tab", '\t'},
+	{"Pipe (|)", '|'},
+}
+
+// ExportDialog 选择导出格式和各格式的选项
+type ExportDialog struct {
+	dialog    dialog.Dialog
+	window    fyne.Window
+	dialect   service.Dialect
+	onConfirm func(opts service.ExportOptions)
+
+	formatSelect    *widget.Select
+	delimiterSelect *widget.Select
+	encodingSelect  *widget.Select
+	tableEntry      *widget.Entry
+	batchEntry      *widget.Entry
+}
+
+// NewExportDialog table 为 INSERT 语句默认的目标表，dialect 决定 INSERT 语句的写法
+func NewExportDialog(window fyne.Window, table string, dialect service.Dialect) *ExportDialog {
+	d := &ExportDialog{
+		window:     window,
+		dialect:    dialect,
+		tableEntry: widget.NewEntry(),
+		batchEntry: widget.NewEntry(),
+	}
+	d.tableEntry.SetText(table)
+	d.batchEntry.SetPlaceHolder("100")
+
+	var delimiters []string
+	for _, delimiter := range csvDelimiters {
+		delimiters = append(delimiters, delimiter.name)
+	}
+	d.delimiterSelect = widget.NewSelect(delimiters, nil)
+	d.delimiterSelect.SetSelectedIndex(0)
+	d.encodingSelect = widget.NewSelect([]string{service.EncodingUTF8, service.EncodingUTF8BOM, service.EncodingGBK}, nil)
+	d.encodingSelect.SetSelected(service.EncodingUTF8BOM)
+
+	// 只显示所选格式用到的选项
+	csvOptions := widget.NewForm(
+		widget.NewFormItem("Delimiter", d.delimiterSelect),
+		widget.NewFormItem("Encoding", d.encodingSelect),
+	)
+	insertOptions := widget.NewForm(
+		widget.NewFormItem("Table", d.tableEntry),
+		widget.NewFormItem("Rows per INSERT", d.batchEntry),
+	)
+	var formats []string
+	for _, format := range service.ExportFormats() {
+		formats = append(formats, string(format))
+	}
+	d.formatSelect = widget.NewSelect(formats, func(selected string) {
+		csvOptions.Hidden = selected != string(service.ExportCSV)
+		insertOptions.Hidden = selected != string(service.ExportInsert)
+		csvOptions.Refresh()
+		insertOptions.Refresh()
+	})
+	d.formatSelect.SetSelected(string(service.ExportCSV))
+
+	content := container.NewVBox(
+		widget.NewForm(widget.NewFormItem("Format", d.formatSelect)),
+		csvOptions,
+		insertOptions,
+		widget.NewButton("Export...", d.confirm),
+	)
+	d.dialog = dialog.NewCustom("Export Results", "Cancel", content, window)
+	d.dialog.Resize(fyne.NewSize(400, 300))
+	return d
+}
+
+func (d *ExportDialog) Show() {
+	d.dialog.Show()
+}
+
+func (d *ExportDialog) SetOnConfirm(callback func(opts service.ExportOptions)) {
+	d.onConfirm = callback
+}
+
+func (d *ExportDialog) confirm() {
+	opts := service.ExportOptions{
+		Format:    service.ExportFormat(d.formatSelect.Selected),
+		Delimiter: csvDelimiters[d.delimiterSelect.SelectedIndex()].value,
+		Encoding:  d.encodingSelect.Selected,
+		Table:     strings.TrimSpace(d.tableEntry.Text),
+		Dialect:   d.dialect,
+	}
+	if opts.Format == service.ExportInsert {
+		if opts.Table == "" {
+			dialog.ShowError(fmt.Errorf("Please enter the target table"), d.window)
+			return
+		}
+		if text := strings.TrimSpace(d.batchEntry.Text); text != "" {
+			batch, err := strconv.Atoi(text)
+			if err != nil || batch < 1 {
+				dialog.ShowError(fmt.Errorf("Rows per INSERT must be a positive number"), d.window)
+				return
+			}
+			opts.BatchSize = batch
+		}
+	}
+	d.dialog.Hide()
+	if d.onConfirm != nil {
+		d.onConfirm(opts)
+	}
+}
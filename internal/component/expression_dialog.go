@@ -0,0 +1,204 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+const (
+	exprCustom     = "Custom"
+	exprConcat     = "CONCAT"
+	exprDateFormat = "DATE_FORMAT"
+	exprCaseWhen   = "CASE WHEN"
+)
+
+// ExpressionDialog 计算列编辑对话框，表达式中用 {列名} 引用当前表的列
+type ExpressionDialog struct {
+	dialog    dialog.Dialog
+	window    fyne.Window
+	columns   []string
+	onConfirm func(name, expression string)
+
+	nameEntry  *widget.Entry
+	modeSelect *widget.Select
+	builders   map[string]func() (string, error) // 各模式生成表达式的方法
+}
+
+func NewExpressionDialog(window fyne.Window, columns []string) *ExpressionDialog {
+	d := &ExpressionDialog{
+		window:    window,
+		columns:   columns,
+		nameEntry: widget.NewEntry(),
+		builders:  make(map[string]func() (string, error)),
+	}
+	d.nameEntry.SetPlaceHolder("column name")
+
+	forms := map[string]fyne.CanvasObject{
+		exprCustom:     d.customForm(),
+		exprConcat:     d.concatForm(),
+		exprDateFormat: d.dateFormatForm(),
+		exprCaseWhen:   d.caseWhenForm(),
+	}
+	body := container.NewStack()
+	d.modeSelect = widget.NewSelect([]string{exprCustom, exprConcat, exprDateFormat, exprCaseWhen}, func(mode string) {
+		body.Objects = []fyne.CanvasObject{forms[mode]}
+		body.Refresh()
+	})
+	d.modeSelect.SetSelected(exprCustom)
+
+	confirmBtn := widget.NewButton("Confirm", d.confirm)
+	content := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Name", d.nameEntry),
+			widget.NewFormItem("Builder", d.modeSelect),
+		),
+		body,
+		confirmBtn,
+	)
+
+	d.dialog = dialog.NewCustom("Expression Column", "Cancel", content, window)
+	d.dialog.Resize(fyne.NewSize(500, 400))
+	return d
+}
+
+func (d *ExpressionDialog) Show() {
+	d.dialog.Show()
+}
+
+func (d *ExpressionDialog) SetOnConfirm(callback func(name, expression string)) {
+	d.onConfirm = callback
+}
+
+func (d *ExpressionDialog) confirm() {
+	name := strings.TrimSpace(d.nameEntry.Text)
+	if err := service.ValidateAlias(name); err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	expression, err := d.builders[d.modeSelect.Selected]()
+	if err != nil {
+		dialog.ShowError(err, d.window)
+		return
+	}
+	if d.onConfirm != nil {
+		d.onConfirm(name, expression)
+	}
+	d.dialog.Hide()
+}
+
+func (d *ExpressionDialog) customForm() fyne.CanvasObject {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("e.g. {price} * {quantity}")
+	d.builders[exprCustom] = func() (string, error) {
+		if strings.TrimSpace(entry.Text) == "" {
+			return "", fmt.Errorf("Please enter an expression")
+		}
+		return strings.TrimSpace(entry.Text), nil
+	}
+	return entry
+}
+
+func (d *ExpressionDialog) concatForm() fyne.CanvasObject {
+	var selected []string
+	columns := widget.NewCheckGroup(d.columns, func(checked []string) {
+		selected = checked
+	})
+	separator := widget.NewEntry()
+	separator.SetText(" ")
+	d.builders[exprConcat] = func() (string, error) {
+		if len(selected) == 0 {
+			return "", fmt.Errorf("Please select the columns to concatenate")
+		}
+		// 按表中列的顺序拼接
+		var ordered []string
+		for _, col := range d.columns {
+			for _, s := range selected {
+				if s == col {
+					ordered = append(ordered, col)
+				}
+			}
+		}
+		return service.ConcatExpression(ordered, separator.Text), nil
+	}
+	return container.NewVBox(
+		widget.NewForm(widget.NewFormItem("Separator", separator)),
+		container.NewVScroll(columns),
+	)
+}
+
+func (d *ExpressionDialog) dateFormatForm() fyne.CanvasObject {
+	column := widget.NewSelect(d.columns, nil)
+	format := widget.NewEntry()
+	format.SetText("%Y-%m-%d")
+	d.builders[exprDateFormat] = func() (string, error) {
+		if column.Selected == "" {
+			return "", fmt.Errorf("Please select a column")
+		}
+		return service.DateFormatExpression(column.Selected, format.Text), nil
+	}
+	return widget.NewForm(
+		widget.NewFormItem("Column", column),
+		widget.NewFormItem("Format", format),
+	)
+}
+
+// caseWhenForm CASE WHEN 构建器，每行一个 WHEN 分支
+func (d *ExpressionDialog) caseWhenForm() fyne.CanvasObject {
+	type branchRow struct {
+		column, operator *widget.Select
+		value, result    *widget.Entry
+	}
+	var rows []*branchRow
+	rowsBox := container.NewVBox()
+
+	var operators []string
+	for _, op := range service.OperatorsForType("") {
+		if op != service.OpIn && op != service.OpNotIn && op != service.OpBetween {
+			operators = append(operators, string(op))
+		}
+	}
+	addRow := func() {
+		row := &branchRow{
+			column:   widget.NewSelect(d.columns, nil),
+			operator: widget.NewSelect(operators, nil),
+			value:    widget.NewEntry(),
+			result:   widget.NewEntry(),
+		}
+		row.operator.SetSelected(string(service.OpEqual))
+		row.value.SetPlaceHolder("value")
+		row.result.SetPlaceHolder("then")
+		rows = append(rows, row)
+		rowsBox.Add(container.NewGridWithColumns(4, row.column, row.operator, row.value, row.result))
+	}
+	addRow()
+
+	elseEntry := widget.NewEntry()
+	elseEntry.SetPlaceHolder("else (optional)")
+	d.builders[exprCaseWhen] = func() (string, error) {
+		var branches []service.CaseBranch
+		for _, row := range rows {
+			if row.column.Selected == "" {
+				continue
+			}
+			branches = append(branches, service.CaseBranch{
+				Column:   row.column.Selected,
+				Operator: service.FilterOperator(row.operator.Selected),
+				Value:    row.value.Text,
+				Result:   row.result.Text,
+			})
+		}
+		return service.CaseExpression(branches, elseEntry.Text)
+	}
+
+	return container.NewVBox(
+		rowsBox,
+		widget.NewButton("+ When", addRow),
+		elseEntry,
+	)
+}
@@ -0,0 +1,268 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// FilterPanel WHERE/HAVING 条件编辑面板，支持 AND/OR 条件组的嵌套
+type FilterPanel struct {
+	window     fyne.Window
+	root       *service.FilterGroup
+	columns    func() map[string][]model.Column // 画布上的表 -> 列信息
+	content    *fyne.Container
+	aggregates bool   // 条件中是否可以选择聚合函数（HAVING）
+	onChanged  func() // 条件变化后调用
+}
+
+func NewFilterPanel(window fyne.Window, columns func() map[string][]model.Column) *FilterPanel {
+	p := &FilterPanel{
+		window:  window,
+		root:    service.NewFilterGroup(service.LogicAnd),
+		columns: columns,
+		content: container.NewVBox(),
+	}
+	p.refresh()
+	return p
+}
+
+// NewHavingPanel 创建HAVING条件面板，条件可以作用于聚合表达式
+func NewHavingPanel(window fyne.Window, columns func() map[string][]model.Column) *FilterPanel {
+	p := NewFilterPanel(window, columns)
+	p.aggregates = true
+	return p
+}
+
+// Container 返回面板的界面元素
+func (p *FilterPanel) Container() fyne.CanvasObject {
+	return p.content
+}
+
+// Filter 返回当前的条件组
+func (p *FilterPanel) Filter() *service.FilterGroup {
+	return p.root
+}
+
+// SetFilter 替换当前的条件组，nil 表示清空
+func (p *FilterPanel) SetFilter(group *service.FilterGroup) {
+	if group == nil {
+		group = service.NewFilterGroup(service.LogicAnd)
+	}
+	p.root = group
+	p.refresh()
+}
+
+// SetOnChanged 设置条件变化后的回调
+func (p *FilterPanel) SetOnChanged(callback func()) {
+	p.onChanged = callback
+}
+
+// Reset 清空所有条件
+func (p *FilterPanel) Reset() {
+	p.root = service.NewFilterGroup(service.LogicAnd)
+	p.refresh()
+}
+
+// refresh 根据条件模型重建界面
+func (p *FilterPanel) refresh() {
+	p.content.Objects = []fyne.CanvasObject{p.renderGroup(p.root, nil)}
+	p.content.Refresh()
+	p.changed()
+}
+
+func (p *FilterPanel) changed() {
+	if p.onChanged != nil {
+		p.onChanged()
+	}
+}
+
+func (p *FilterPanel) renderGroup(group, parent *service.FilterGroup) fyne.CanvasObject {
+	logicSelect := widget.NewSelect([]string{string(service.LogicAnd), string(service.LogicOr)}, nil)
+	logicSelect.SetSelected(string(group.Logic))
+	logicSelect.OnChanged = func(selected string) {
+		group.Logic = service.LogicOperator(selected)
+		p.changed()
+	}
+
+	header := container.NewHBox(
+		logicSelect,
+		widget.NewButton("+ Condition", func() { p.showConditionDialog(group) }),
+		widget.NewButton("+ Group", func() {
+			group.Groups = append(group.Groups, service.NewFilterGroup(service.LogicAnd))
+			p.refresh()
+		}),
+	)
+	if parent != nil {
+		header.Add(widget.NewButton("Remove", func() {
+			parent.Groups = removeGroup(parent.Groups, group)
+			p.refresh()
+		}))
+	}
+
+	body := container.NewVBox()
+	for _, cond := range group.Conditions {
+		cond := cond
+		body.Add(container.NewBorder(nil, nil, nil,
+			widget.NewButton("x", func() {
+				group.Conditions = removeCondition(group.Conditions, cond)
+				p.refresh()
+			}),
+			widget.NewLabel(cond.String()),
+		))
+	}
+	for _, child := range group.Groups {
+		body.Add(p.renderGroup(child, group))
+	}
+
+	// 子组通过左侧缩进体现层级
+	return container.NewVBox(header, container.NewBorder(nil, nil, widget.NewLabel("  "), nil, body))
+}
+
+// showConditionDialog 弹出条件编辑表单，运算符根据列类型确定
+func (p *FilterPanel) showConditionDialog(group *service.FilterGroup) {
+	tableColumns := p.columns()
+	if len(tableColumns) == 0 {
+		dialog.ShowError(fmt.Errorf("Please add a table first"), p.window)
+		return
+	}
+
+	var tables []string
+	for table := range tableColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	cond := &service.Condition{}
+	valueEntry := widget.NewEntry()
+	valueEntry.SetPlaceHolder("value, or a,b,c for IN")
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("upper bound for BETWEEN")
+	toEntry.Disable()
+	paramCheck := widget.NewCheck("Use ? parameter", nil)
+
+	operatorSelect := widget.NewSelect(nil, func(selected string) {
+		cond.Operator = service.FilterOperator(selected)
+		if cond.Operator == service.OpBetween {
+			toEntry.Enable()
+		} else {
+			toEntry.Disable()
+		}
+	})
+	updateOperators := func() {
+		var operators []string
+		for _, op := range service.OperatorsForType(cond.Aggregate.ResultType(cond.DataType)) {
+			operators = append(operators, string(op))
+		}
+		operatorSelect.Options = operators
+		operatorSelect.SetSelected(operators[0])
+	}
+	columnSelect := widget.NewSelect(nil, func(selected string) {
+		cond.Column = selected
+		cond.DataType = columnType(tableColumns[cond.Table], selected)
+		updateOperators()
+	})
+	tableSelect := widget.NewSelect(tables, func(selected string) {
+		cond.Table = selected
+		var names []string
+		for _, col := range tableColumns[selected] {
+			names = append(names, col.Name)
+		}
+		columnSelect.Options = names
+		columnSelect.ClearSelected()
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Table", tableSelect),
+		widget.NewFormItem("Column", columnSelect),
+	}
+	if p.aggregates {
+		aggregateOptions := []string{noAggregate}
+		for _, agg := range service.AggregateFuncs() {
+			aggregateOptions = append(aggregateOptions, string(agg))
+		}
+		aggregateSelect := widget.NewSelect(aggregateOptions, func(selected string) {
+			cond.Aggregate = service.AggNone
+			if selected != noAggregate {
+				cond.Aggregate = service.AggregateFunc(selected)
+			}
+			if cond.Column != "" {
+				updateOperators()
+			}
+		})
+		items = append(items, widget.NewFormItem("Aggregate", aggregateSelect))
+	}
+	items = append(items,
+		widget.NewFormItem("Operator", operatorSelect),
+		widget.NewFormItem("Value", valueEntry),
+		widget.NewFormItem("And", toEntry),
+		widget.NewFormItem("", paramCheck),
+	)
+	dialog.ShowForm("Add Condition", "Add", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if cond.Table == "" || cond.Column == "" || cond.Operator == "" {
+			dialog.ShowError(fmt.Errorf("Please select a table, column and operator"), p.window)
+			return
+		}
+		cond.Parameter = paramCheck.Checked
+		cond.Values = conditionValues(cond.Operator, valueEntry.Text, toEntry.Text)
+		group.Conditions = append(group.Conditions, cond)
+		p.refresh()
+	}, p.window)
+}
+
+// conditionValues 根据运算符解析输入的比较值
+func conditionValues(op service.FilterOperator, value, to string) []string {
+	switch op {
+	case service.OpIsNull, service.OpIsNotNull:
+		return nil
+	case service.OpBetween:
+		return []string{value, to}
+	case service.OpIn, service.OpNotIn:
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	default:
+		return []string{value}
+	}
+}
+
+func columnType(columns []model.Column, name string) string {
+	for _, col := range columns {
+		if col.Name == name {
+			return col.Type
+		}
+	}
+	return ""
+}
+
+func removeCondition(conditions []*service.Condition, target *service.Condition) []*service.Condition {
+	for i, cond := range conditions {
+		if cond == target {
+			return append(conditions[:i], conditions[i+1:]...)
+		}
+	}
+	return conditions
+}
+
+func removeGroup(groups []*service.FilterGroup, target *service.FilterGroup) []*service.FilterGroup {
+	for i, group := range groups {
+		if group == target {
+			return append(groups[:i], groups[i+1:]...)
+		}
+	}
+	return groups
+}
@@ -8,179 +8,139 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
 	"github.com/lowSqlGen/internal/service"
 )
 
 type JoinDialog struct {
 	dialog      dialog.Dialog
+	window      fyne.Window
 	sourceTable string
 	dbService   service.DatabaseService
 	dbName      string
-	onConfirm   func(targetTable, sourceColumn, targetColumn string)
-
-	// 选中的列
-	selectedSourceColumn string
-	selectedTargetColumn string
-	selectedTable        string
-	sourceRadio          *widget.RadioGroup
-	targetRadio          *widget.RadioGroup
+	onConfirm   func(selection *JoinSelection)
+
+	sourceColumns    []model.Column
+	targetColumns    []model.Column
+	sourceIndexes    []model.Index // 用于提示连接列缺少索引
+	targetIndexes    []model.Index
+	selectedTable    string
+	selectedJoinType service.JoinType
+
+	// 条件编辑行
+	pairRows   []*joinPairRow
+	filterRows []*joinFilterRow
+	pairsBox   *fyne.Container
+	filtersBox *fyne.Container
+	tableLabel *widget.Label
+	tablesList *widget.List
+	tables     []string
+
+	// 外键或命名推测得到的连接推荐
+	suggestions []service.JoinSuggestion
 }
 
 func NewJoinDialog(window fyne.Window, sourceTable string, dbService service.DatabaseService, dbName string) *JoinDialog {
 	j := &JoinDialog{
+		window:      window,
 		sourceTable: sourceTable,
 		dbService:   dbService,
 		dbName:      dbName,
+
+		selectedJoinType: service.LeftJoin,
+		pairsBox:         container.NewVBox(),
+		filtersBox:       container.NewVBox(),
+		tableLabel:       widget.NewLabel("Target: -"),
 	}
 
-	// 创建源表列表（左侧）
-	sourceColumns, _ := dbService.GetColumns(dbName, sourceTable)
-	var sourceListVar *widget.List
-	sourceListVar = widget.NewList(
-		func() int { return len(sourceColumns) },
-		func() fyne.CanvasObject {
-			check := widget.NewCheck("", nil)
-			check.Checked = false
-			return container.NewHBox(check, widget.NewLabel("template"))
-		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			check := obj.(*fyne.Container).Objects[0].(*widget.Check)
-			label := obj.(*fyne.Container).Objects[1].(*widget.Label)
-			label.SetText(sourceColumns[id])
-
-			// 设置选中状态
-			check.Checked = (j.selectedSourceColumn == sourceColumns[id])
-			check.OnChanged = func(checked bool) {
-				if checked {
-					// 取消其他选中状态
-					for i := 0; i < sourceListVar.Length(); i++ {
-						if i != id {
-							itemContainer := sourceListVar.CreateItem()
-							itemCheck := itemContainer.(*fyne.Container).Objects[0].(*widget.Check)
-							itemCheck.Checked = false
-						}
-					}
-					j.selectedSourceColumn = sourceColumns[id]
-				} else {
-					j.selectedSourceColumn = ""
-				}
-				sourceListVar.Refresh()
-			}
+	// 创建连接类型选择
+	var joinTypeOptions []string
+	for _, joinType := range service.JoinTypes() {
+		joinTypeOptions = append(joinTypeOptions, string(joinType))
+	}
+	joinTypeSelect := widget.NewSelect(joinTypeOptions, func(selected string) {
+		j.selectedJoinType = service.JoinType(selected)
+	})
+	joinTypeSelect.SetSelected(string(j.selectedJoinType))
 
-		},
-	)
+	j.sourceColumns, _ = dbService.GetColumns(dbName, sourceTable)
+	j.sourceIndexes, _ = dbService.GetIndexes(dbName, sourceTable)
 
-	// 创建表列表（中间）
-	tables, _ := dbService.GetTables(dbName)
-	var targetColumns []string
-	var targetListVar *widget.List
-	targetListVar = widget.NewList(
-		func() int { return len(targetColumns) },
-		func() fyne.CanvasObject {
-			check := widget.NewCheck("", nil)
-			check.Checked = false
-			return container.NewHBox(check, widget.NewLabel("template"))
-		},
-		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			check := obj.(*fyne.Container).Objects[0].(*widget.Check)
-			label := obj.(*fyne.Container).Objects[1].(*widget.Label)
-			label.SetText(targetColumns[id])
-
-			// 设置选中状态
-			check.Checked = (j.selectedTargetColumn == targetColumns[id])
-			check.OnChanged = func(checked bool) {
-				if checked {
-					// 取消其他选中状态
-					for i := 0; i < targetListVar.Length(); i++ {
-						if i != id {
-							itemContainer := targetListVar.CreateItem()
-							itemCheck := itemContainer.(*fyne.Container).Objects[0].(*widget.Check)
-							itemCheck.Checked = false
-						}
-					}
-					j.selectedTargetColumn = targetColumns[id]
-				} else {
-					j.selectedTargetColumn = ""
-				}
-				targetListVar.Refresh()
-			}
-		},
-	)
-
-	// 创建目标表列表（右侧）
-	tablesList := widget.NewList(
-		func() int { return len(tables) },
+	// 创建表列表（左侧）
+	j.tables, _ = dbService.GetTables(dbName)
+	j.tablesList = widget.NewList(
+		func() int { return len(j.tables) },
 		func() fyne.CanvasObject { return widget.NewLabel("template") },
 		func(id widget.ListItemID, obj fyne.CanvasObject) {
-			obj.(*widget.Label).SetText(tables[id])
+			obj.(*widget.Label).SetText(j.tables[id])
 		},
 	)
 
 	// 表选择事件
-	tablesList.OnSelected = func(id widget.ListItemID) {
-		j.selectedTable = tables[id]
-		targetColumns, _ = dbService.GetColumns(dbName, j.selectedTable)
-		// 重置目标列选择
-		j.selectedTargetColumn = ""
-		targetListVar.Refresh()
-	}
-
-	// 修改确认按钮的检查逻辑
-	confirmBtn := widget.NewButton("Confirm", func() {
-		if j.selectedSourceColumn == "" {
-			dialog.ShowError(fmt.Errorf("Please select a source column"), window)
-			return
-		}
-		if j.selectedTable == "" {
-			dialog.ShowError(fmt.Errorf("Please select a target table"), window)
-			return
-		}
-		if j.selectedTargetColumn == "" {
-			dialog.ShowError(fmt.Errorf("Please select a target column"), window)
-			return
-		}
+	j.tablesList.OnSelected = func(id widget.ListItemID) {
+		j.selectTable(j.tables[id])
+	}
 
-		if j.onConfirm != nil {
-			j.onConfirm(j.selectedTable, j.selectedSourceColumn, j.selectedTargetColumn)
-		}
-		j.dialog.Hide()
-	})
+	// 连接推荐，选择后自动选中目标表并填入列对
+	foreignKeys, _ := dbService.GetForeignKeys(dbName, sourceTable)
+	j.suggestions = service.SuggestJoins(sourceTable, model.ColumnNames(j.sourceColumns), j.tables, foreignKeys,
+		func(table string) ([]string, error) {
+			columns, err := dbService.GetColumns(dbName, table)
+			return model.ColumnNames(columns), err
+		})
+	var suggestionOptions []string
+	for _, suggestion := range j.suggestions {
+		suggestionOptions = append(suggestionOptions, suggestion.String())
+	}
+	suggestionSelect := widget.NewSelect(suggestionOptions, nil)
+	suggestionSelect.PlaceHolder = "No suggestions"
+	if len(j.suggestions) > 0 {
+		suggestionSelect.PlaceHolder = "Suggested joins"
+	}
+	suggestionSelect.OnChanged = func(string) {
+		j.applySuggestion(j.suggestions[suggestionSelect.SelectedIndex()])
+	}
 
-	// 设置列表的最小大小
-	sourceListVar.Resize(fyne.NewSize(200, 400))
-	tablesList.Resize(fyne.NewSize(200, 400))
-	targetListVar.Resize(fyne.NewSize(200, 400))
+	confirmBtn := widget.NewButton("Confirm", j.confirm)
 
 	// 创建滚动容器来包装列表
-	sourceScroll := container.NewVScroll(sourceListVar)
-	tablesScroll := container.NewVScroll(tablesList)
-	targetScroll := container.NewVScroll(targetListVar)
-
-	// 设置滚动容器的最小大小
-	sourceScroll.SetMinSize(fyne.NewSize(200, 400))
+	tablesScroll := container.NewVScroll(j.tablesList)
 	tablesScroll.SetMinSize(fyne.NewSize(200, 400))
-	targetScroll.SetMinSize(fyne.NewSize(200, 400))
+
+	conditionsScroll := container.NewVScroll(container.NewVBox(
+		j.tableLabel,
+		widget.NewLabel("ON Conditions"),
+		j.pairsBox,
+		widget.NewButton("+ Condition", func() { j.addPairRow() }),
+		widget.NewLabel("Extra Conditions"),
+		j.filtersBox,
+		widget.NewButton("+ Extra", func() { j.addFilterRow() }),
+	))
+	conditionsScroll.SetMinSize(fyne.NewSize(560, 400))
 
 	// 修改对话框内容布局
 	content := container.NewHBox(
-		container.NewVBox(
-			widget.NewLabel("Source Columns"),
-			sourceScroll,
-		),
 		container.NewVBox(
 			widget.NewLabel("Tables"),
 			tablesScroll,
 		),
-		container.NewVBox(
-			widget.NewLabel("Target Columns"),
-			targetScroll,
-		),
+		conditionsScroll,
 	)
 
 	// 创建对话框，使用更大的尺寸
 	j.dialog = dialog.NewCustom("Join Tables", "Cancel",
-		container.NewVBox(content, confirmBtn), window)
-	j.dialog.Resize(fyne.NewSize(700, 500))
+		container.NewVBox(
+			container.NewHBox(widget.NewLabel("Join Type"), joinTypeSelect,
+				widget.NewLabel("Suggestion"), suggestionSelect),
+			content,
+			confirmBtn,
+		), window)
+	j.dialog.Resize(fyne.NewSize(820, 540))
+
+	// 预先选中第一个推荐
+	if len(j.suggestions) > 0 {
+		suggestionSelect.SetSelectedIndex(0)
+	}
 
 	return j
 }
@@ -189,6 +149,92 @@ func (j *JoinDialog) Show() {
 	j.dialog.Show()
 }
 
-func (j *JoinDialog) SetOnConfirm(callback func(targetTable, sourceColumn, targetColumn string)) {
+func (j *JoinDialog) SetOnConfirm(callback func(selection *JoinSelection)) {
 	j.onConfirm = callback
 }
+
+// selectTable 选择目标表后加载其列并重置条件
+func (j *JoinDialog) selectTable(table string) {
+	j.selectedTable = table
+	j.targetColumns, _ = j.dbService.GetColumns(j.dbName, table)
+	j.targetIndexes, _ = j.dbService.GetIndexes(j.dbName, table)
+	j.tableLabel.SetText("Target: " + table)
+
+	j.pairRows = nil
+	j.filterRows = nil
+	j.pairsBox.Objects = nil
+	j.filtersBox.Objects = nil
+	j.filtersBox.Refresh()
+
+	// 该表有推荐时使用推荐的列对
+	for _, suggestion := range j.suggestions {
+		if suggestion.TargetTable == table {
+			j.fillPairs(suggestion)
+			return
+		}
+	}
+	j.addPairRow()
+}
+
+// applySuggestion 选中推荐的目标表并填入列对
+func (j *JoinDialog) applySuggestion(suggestion service.JoinSuggestion) {
+	for i, table := range j.tables {
+		if table == suggestion.TargetTable {
+			j.tablesList.Select(i)
+			break
+		}
+	}
+	if j.selectedTable != suggestion.TargetTable {
+		return
+	}
+	j.pairRows = nil
+	j.pairsBox.Objects = nil
+	j.fillPairs(suggestion)
+}
+
+// fillPairs 为推荐的每个列对添加一行等值条件
+func (j *JoinDialog) fillPairs(suggestion service.JoinSuggestion) {
+	for i := range suggestion.SourceColumns {
+		row := j.addPairRow()
+		row.source.SetSelected(suggestion.SourceColumns[i])
+		row.target.SetSelected(suggestion.TargetColumns[i])
+	}
+	j.pairsBox.Refresh()
+}
+
+func (j *JoinDialog) confirm() {
+	if j.selectedTable == "" {
+		dialog.ShowError(fmt.Errorf("Please select a target table"), j.window)
+		return
+	}
+
+	selection := &JoinSelection{
+		TargetTable: j.selectedTable,
+		JoinType:    j.selectedJoinType,
+	}
+	for _, row := range j.pairRows {
+		pair, ok, err := row.pair()
+		if err != nil {
+			dialog.ShowError(err, j.window)
+			return
+		}
+		if ok {
+			selection.Pairs = append(selection.Pairs, pair)
+		}
+	}
+	for _, row := range j.filterRows {
+		if filter, ok := row.filter(); ok {
+			selection.Filters = append(selection.Filters, filter)
+		}
+	}
+
+	if j.selectedJoinType.NeedsCondition() && len(selection.Pairs)+len(selection.Filters) == 0 {
+		dialog.ShowError(fmt.Errorf("Please add at least one join condition"), j.window)
+		return
+	}
+
+	if j.onConfirm != nil {
+		j.onConfirm(selection)
+	}
+	j.dialog.Hide()
+}
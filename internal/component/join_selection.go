@@ -0,0 +1,262 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// JoinSelection 连接对话框的结果，列按源表/目标表区分，
+// 目标节点创建后再转换为按实例ID引用的连接条件
+type JoinSelection struct {
+	TargetTable string
+	JoinType    service.JoinType
+	Pairs       []JoinColumnPair
+	Filters     []JoinFilter
+}
+
+// JoinColumnPair 源表列与目标表列之间的比较
+type JoinColumnPair struct {
+	SourceColumn  string
+	Operator      service.FilterOperator
+	TargetColumn  string
+	TargetColumn2 string // BETWEEN 的上界
+}
+
+// JoinFilter ON 子句中作用于某一侧的字面量条件
+type JoinFilter struct {
+	OnTarget bool
+	Column   string
+	DataType string
+	Operator service.FilterOperator
+	Values   []string
+}
+
+// Conditions 把选择结果转换为按实例ID引用的连接条件
+func (s *JoinSelection) Conditions(sourceID, targetID string) ([]service.JoinCondition, []*service.Condition) {
+	var conditions []service.JoinCondition
+	for _, pair := range s.Pairs {
+		cond := service.JoinCondition{
+			Left:     service.ColumnRef{Instance: sourceID, Column: pair.SourceColumn},
+			Operator: pair.Operator,
+			Right:    service.ColumnRef{Instance: targetID, Column: pair.TargetColumn},
+		}
+		if pair.Operator == service.OpBetween {
+			cond.Right2 = service.ColumnRef{Instance: targetID, Column: pair.TargetColumn2}
+		}
+		conditions = append(conditions, cond)
+	}
+
+	var filters []*service.Condition
+	for _, f := range s.Filters {
+		table := sourceID
+		if f.OnTarget {
+			table = targetID
+		}
+		filters = append(filters, &service.Condition{
+			Table:    table,
+			Column:   f.Column,
+			DataType: f.DataType,
+			Operator: f.Operator,
+			Values:   f.Values,
+		})
+	}
+	return conditions, filters
+}
+
+// joinPairRow 列比较条件的编辑行
+type joinPairRow struct {
+	source, operator, target, target2 *widget.Select
+	types                             *widget.Label // 两侧列的类型
+}
+
+func (j *JoinDialog) addPairRow() *joinPairRow {
+	var operators []string
+	for _, op := range service.JoinOperators() {
+		operators = append(operators, string(op))
+	}
+	row := &joinPairRow{
+		source:  widget.NewSelect(model.ColumnNames(j.sourceColumns), nil),
+		target:  widget.NewSelect(model.ColumnNames(j.targetColumns), nil),
+		target2: widget.NewSelect(model.ColumnNames(j.targetColumns), nil),
+		types:   widget.NewLabel(""),
+	}
+	row.source.OnChanged = func(string) { j.refreshPairInfo() }
+	row.target.OnChanged = func(string) { j.refreshPairInfo() }
+	row.target2.Disable()
+	row.operator = widget.NewSelect(operators, func(selected string) {
+		if service.FilterOperator(selected) == service.OpBetween {
+			row.target2.Enable()
+		} else {
+			row.target2.Disable()
+		}
+	})
+	row.operator.SetSelected(string(service.OpEqual))
+
+	var rowBox *fyne.Container
+	removeBtn := widget.NewButton("x", func() {
+		j.pairsBox.Remove(rowBox)
+		for i, r := range j.pairRows {
+			if r == row {
+				j.pairRows = append(j.pairRows[:i], j.pairRows[i+1:]...)
+				break
+			}
+		}
+		j.refreshPairInfo()
+	})
+	rowBox = container.NewVBox(
+		container.NewBorder(nil, nil, nil, removeBtn,
+			container.NewGridWithColumns(4, row.source, row.operator, row.target, row.target2)),
+		row.types,
+	)
+
+	j.pairRows = append(j.pairRows, row)
+	j.pairsBox.Add(rowBox)
+	return row
+}
+
+// refreshPairInfo 更新各条件行的类型和索引提示。
+// 复合连接的各列一起决定能否使用复合索引，因此任一行变化时全部更新
+func (j *JoinDialog) refreshPairInfo() {
+	var sources, targets []string
+	for _, row := range j.pairRows {
+		sources = append(sources, row.source.Selected)
+		targets = append(targets, row.target.Selected)
+	}
+	for _, row := range j.pairRows {
+		text := pairTypesText(j.sourceColumns, row.source.Selected, j.targetColumns, row.target.Selected)
+		row.types.Importance = widget.MediumImportance
+		if warning := j.pairIndexText(row, sources, targets); warning != "" {
+			text += "  " + warning
+			row.types.Importance = widget.WarningImportance
+		}
+		row.types.SetText(text)
+	}
+}
+
+// pairIndexText 连接列在任一侧没有可用的索引时给出提示，这样的连接需要扫描整张表
+func (j *JoinDialog) pairIndexText(row *joinPairRow, sources, targets []string) string {
+	source, target := row.source.Selected, row.target.Selected
+	if source == "" || target == "" {
+		return ""
+	}
+	var missing []string
+	if !service.IndexUsable(j.sourceIndexes, source, sources) {
+		missing = append(missing, j.sourceTable+"."+source)
+	}
+	if !service.IndexUsable(j.targetIndexes, target, targets) {
+		missing = append(missing, j.selectedTable+"."+target)
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return "(no index on " + strings.Join(missing, ", ") + ")"
+}
+
+// pairTypesText 显示两侧列的类型，类型类别不一致时给出提示
+func pairTypesText(sourceColumns []model.Column, source string, targetColumns []model.Column, target string) string {
+	if source == "" || target == "" {
+		return ""
+	}
+	sourceType := columnType(sourceColumns, source)
+	targetType := columnType(targetColumns, target)
+	text := fmt.Sprintf("%s ↔ %s", sourceType, targetType)
+	sourceKind, targetKind := service.ColumnKind(sourceType), service.ColumnKind(targetType)
+	if sourceKind != service.KindUnknown && targetKind != service.KindUnknown && sourceKind != targetKind {
+		text += " (type mismatch)"
+	}
+	return text
+}
+
+// pair 读取编辑行，未填写的行被忽略，填写不完整时返回错误
+func (r *joinPairRow) pair() (JoinColumnPair, bool, error) {
+	pair := JoinColumnPair{
+		SourceColumn:  r.source.Selected,
+		Operator:      service.FilterOperator(r.operator.Selected),
+		TargetColumn:  r.target.Selected,
+		TargetColumn2: r.target2.Selected,
+	}
+	if pair.SourceColumn == "" && pair.TargetColumn == "" {
+		return pair, false, nil
+	}
+	if pair.SourceColumn == "" || pair.TargetColumn == "" ||
+		(pair.Operator == service.OpBetween && pair.TargetColumn2 == "") {
+		return pair, false, fmt.Errorf("Please complete the join condition on %s", pair.SourceColumn+pair.TargetColumn)
+	}
+	return pair, true, nil
+}
+
+// joinFilterRow 字面量条件的编辑行
+type joinFilterRow struct {
+	side, column, operator *widget.Select
+	value                  *widget.Entry
+	columns                []model.Column // 当前所选一侧的列
+}
+
+const (
+	joinSideSource = "source"
+	joinSideTarget = "target"
+)
+
+func (j *JoinDialog) addFilterRow() *joinFilterRow {
+	var operators []string
+	for _, op := range service.OperatorsForType("") {
+		if op != service.OpBetween {
+			operators = append(operators, string(op))
+		}
+	}
+	row := &joinFilterRow{
+		column:   widget.NewSelect(nil, nil),
+		operator: widget.NewSelect(operators, nil),
+		value:    widget.NewEntry(),
+	}
+	row.side = widget.NewSelect([]string{joinSideSource, joinSideTarget}, func(selected string) {
+		if selected == joinSideSource {
+			row.columns = j.sourceColumns
+		} else {
+			row.columns = j.targetColumns
+		}
+		row.column.Options = model.ColumnNames(row.columns)
+		row.column.ClearSelected()
+	})
+	row.side.SetSelected(joinSideTarget)
+	row.operator.SetSelected(string(service.OpEqual))
+	row.value.SetPlaceHolder("value")
+
+	var rowBox *fyne.Container
+	removeBtn := widget.NewButton("x", func() {
+		j.filtersBox.Remove(rowBox)
+		for i, r := range j.filterRows {
+			if r == row {
+				j.filterRows = append(j.filterRows[:i], j.filterRows[i+1:]...)
+				break
+			}
+		}
+	})
+	rowBox = container.NewBorder(nil, nil, nil, removeBtn,
+		container.NewGridWithColumns(4, row.side, row.column, row.operator, row.value))
+
+	j.filterRows = append(j.filterRows, row)
+	j.filtersBox.Add(rowBox)
+	return row
+}
+
+// filter 读取编辑行，未选择列的行被忽略
+func (r *joinFilterRow) filter() (JoinFilter, bool) {
+	if r.column.Selected == "" {
+		return JoinFilter{}, false
+	}
+	op := service.FilterOperator(r.operator.Selected)
+	return JoinFilter{
+		OnTarget: r.side.Selected == joinSideTarget,
+		Column:   r.column.Selected,
+		DataType: columnType(r.columns, r.column.Selected),
+		Operator: op,
+		Values:   conditionValues(op, r.value.Text, ""),
+	}, true
+}
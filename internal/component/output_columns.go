@@ -0,0 +1,86 @@
+package gui
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// OutputColumnList 输出列列表，通过上下拖动调整SELECT中列的顺序
+type OutputColumnList struct {
+	content *fyne.Container
+	onMove  func(from, to int)
+}
+
+func NewOutputColumnList(onMove func(from, to int)) *OutputColumnList {
+	return &OutputColumnList{
+		content: container.NewVBox(),
+		onMove:  onMove,
+	}
+}
+
+// Container 返回列表的界面元素
+func (l *OutputColumnList) Container() fyne.CanvasObject {
+	return l.content
+}
+
+// SetItems 按顺序重建列表项
+func (l *OutputColumnList) SetItems(items []string) {
+	l.content.Objects = nil
+	for i, item := range items {
+		l.content.Add(newOutputColumnRow(l, i, item))
+	}
+	l.content.Refresh()
+}
+
+// outputColumnRow 可拖动的列表行
+type outputColumnRow struct {
+	widget.BaseWidget
+	list    *OutputColumnList
+	label   *widget.Label
+	index   int
+	dragged float32 // 本次拖动在垂直方向上的累计距离
+}
+
+func newOutputColumnRow(list *OutputColumnList, index int, text string) *outputColumnRow {
+	r := &outputColumnRow{
+		list:  list,
+		label: widget.NewLabel("≡ " + text),
+		index: index,
+	}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+func (r *outputColumnRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.label)
+}
+
+// Dragged 记录拖动距离
+func (r *outputColumnRow) Dragged(e *fyne.DragEvent) {
+	r.dragged += e.Dragged.DY
+}
+
+// DragEnd 根据拖动距离计算目标位置
+func (r *outputColumnRow) DragEnd() {
+	rowHeight := r.Size().Height + theme.Padding()
+	shift := 0
+	if rowHeight > 0 {
+		shift = int(math.Round(float64(r.dragged / rowHeight)))
+	}
+	r.dragged = 0
+
+	to := r.index + shift
+	if to < 0 {
+		to = 0
+	}
+	if last := len(r.list.content.Objects) - 1; to > last {
+		to = last
+	}
+	if to != r.index && r.list.onMove != nil {
+		r.list.onMove(r.index, to)
+	}
+}
@@ -0,0 +1,49 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// ShowPassphraseDialog 询问主密码并解锁连接配置，第一次使用时要求输入两次以设置主密码。
+// 解锁成功后调用 onUnlocked，取消时调用 onCancel（可以为 nil）
+func ShowPassphraseDialog(window fyne.Window, store *service.ProfileStore, onUnlocked, onCancel func()) {
+	passphrase := widget.NewPasswordEntry()
+	items := []*widget.FormItem{widget.NewFormItem("Master password", passphrase)}
+	title := "Unlock saved connections"
+
+	confirm := widget.NewPasswordEntry()
+	if !store.HasPassphrase() {
+		title = "Set a master password"
+		items = append(items, widget.NewFormItem("Confirm", confirm))
+		items = append(items, widget.NewFormItem("", widget.NewLabel("Saved passwords are encrypted with it.\nIt cannot be recovered if forgotten.")))
+	}
+
+	dialog.ShowForm(title, "OK", "Cancel", items, func(ok bool) {
+		if !ok {
+			if onCancel != nil {
+				onCancel()
+			}
+			return
+		}
+		if !store.HasPassphrase() && passphrase.Text != confirm.Text {
+			dialog.ShowError(fmt.Errorf("The passwords do not match"), window)
+			if onCancel != nil {
+				onCancel()
+			}
+			return
+		}
+		if err := store.Unlock(passphrase.Text); err != nil {
+			dialog.ShowError(err, window)
+			if onCancel != nil {
+				onCancel()
+			}
+			return
+		}
+		onUnlocked()
+	}, window)
+}
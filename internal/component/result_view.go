@@ -0,0 +1,235 @@
+package gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+const (
+	resultPageSize    = 100 // 每页显示的行数
+	resultColumnWidth = 140
+)
+
+// ResultView 查询结果的分页表格，表头显示列名和类型，NULL 以灰色斜体显示
+type ResultView struct {
+	container *fyne.Container
+	table     *widget.Table
+	status    *widget.Label
+	pageLabel *widget.Label
+	prevBtn   *widget.Button
+	nextBtn   *widget.Button
+	cancelBtn *widget.Button
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // 中止正在执行的查询或导出，未执行时为 nil
+	result *service.QueryResult
+	page   int
+}
+
+func NewResultView() *ResultView {
+	v := &ResultView{
+		status:    widget.NewLabel("Run a query to see its results"),
+		pageLabel: widget.NewLabel(""),
+	}
+	v.status.Wrapping = fyne.TextWrapWord
+
+	v.table = widget.NewTableWithHeaders(v.size, func() fyne.CanvasObject {
+		return &widget.Label{Truncation: fyne.TextTruncateEllipsis}
+	}, v.updateCell)
+	v.table.CreateHeader = func() fyne.CanvasObject {
+		return &widget.Label{TextStyle: fyne.TextStyle{Bold: true}, Truncation: fyne.TextTruncateEllipsis}
+	}
+	v.table.UpdateHeader = v.updateHeader
+
+	v.prevBtn = widget.NewButton("Prev", func() { v.showPage(v.page - 1) })
+	v.nextBtn = widget.NewButton("Next", func() { v.showPage(v.page + 1) })
+	v.cancelBtn = widget.NewButton("Cancel", v.Cancel)
+	v.cancelBtn.Importance = widget.DangerImportance
+	v.cancelBtn.Hide()
+	v.updatePager()
+
+	toolbar := container.NewBorder(nil, nil, nil,
+		container.NewHBox(v.cancelBtn, v.prevBtn, v.pageLabel, v.nextBtn),
+		v.status,
+	)
+	v.container = container.NewBorder(toolbar, nil, nil, nil, v.table)
+	return v
+}
+
+func (v *ResultView) Container() fyne.CanvasObject {
+	return v.container
+}
+
+// Start 标记查询或导出开始执行，cancel 用于中止。已有任务在执行时返回 false
+func (v *ResultView) Start(cancel context.CancelFunc) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cancel != nil {
+		return false
+	}
+	v.cancel = cancel
+	v.setStatus("Running...", widget.LowImportance)
+	v.cancelBtn.Show()
+	return true
+}
+
+// Cancel 中止正在执行的查询或导出
+func (v *ResultView) Cancel() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+	}
+}
+
+// Progress 显示正在执行的任务的进度
+func (v *ResultView) Progress(text string) {
+	v.setStatus(text, widget.LowImportance)
+}
+
+// Finish 显示查询结果或错误
+func (v *ResultView) Finish(result *service.QueryResult, err error) {
+	if err != nil {
+		v.FinishTask("", err)
+		return
+	}
+
+	v.result = result
+	summary := fmt.Sprintf("%d rows in %s", len(result.Rows), formatElapsed(result.Elapsed))
+	if result.Truncated {
+		summary = fmt.Sprintf("First %d rows in %s (row limit reached)", len(result.Rows), formatElapsed(result.Elapsed))
+	}
+	v.FinishTask(summary, nil)
+	for i := range result.Columns {
+		v.table.SetColumnWidth(i, resultColumnWidth)
+	}
+	v.showPage(0)
+}
+
+// FinishTask 结束 Start 开始的任务，显示结果摘要或错误，不改变表格中的结果
+func (v *ResultView) FinishTask(summary string, err error) {
+	v.mu.Lock()
+	v.cancel = nil
+	v.mu.Unlock()
+	v.cancelBtn.Hide()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		v.setStatus("Cancelled", widget.WarningImportance)
+	case err != nil:
+		v.setStatus(err.Error(), widget.DangerImportance)
+	default:
+		v.setStatus(summary, widget.MediumImportance)
+	}
+}
+
+func (v *ResultView) setStatus(text string, importance widget.Importance) {
+	v.status.Importance = importance
+	v.status.SetText(text)
+}
+
+// showPage 显示第 page 页，超出范围时显示第一页
+func (v *ResultView) showPage(page int) {
+	if page < 0 || page >= v.pageCount() {
+		page = 0
+	}
+	v.page = page
+	v.updatePager()
+	v.table.ScrollToTop()
+	v.table.Refresh()
+}
+
+func (v *ResultView) pageCount() int {
+	if v.result == nil || len(v.result.Rows) == 0 {
+		return 1
+	}
+	return (len(v.result.Rows) + resultPageSize - 1) / resultPageSize
+}
+
+func (v *ResultView) updatePager() {
+	v.pageLabel.SetText(fmt.Sprintf("Page %d / %d", v.page+1, v.pageCount()))
+	if v.page > 0 {
+		v.prevBtn.Enable()
+	} else {
+		v.prevBtn.Disable()
+	}
+	if v.page+1 < v.pageCount() {
+		v.nextBtn.Enable()
+	} else {
+		v.nextBtn.Disable()
+	}
+}
+
+// pageRows 当前页的行
+func (v *ResultView) pageRows() [][]any {
+	if v.result == nil {
+		return nil
+	}
+	start := v.page * resultPageSize
+	end := start + resultPageSize
+	if end > len(v.result.Rows) {
+		end = len(v.result.Rows)
+	}
+	return v.result.Rows[start:end]
+}
+
+func (v *ResultView) size() (int, int) {
+	if v.result == nil {
+		return 0, 0
+	}
+	return len(v.pageRows()), len(v.result.Columns)
+}
+
+func (v *ResultView) updateCell(id widget.TableCellID, obj fyne.CanvasObject) {
+	label := obj.(*widget.Label)
+	rows := v.pageRows()
+	if id.Row >= len(rows) || id.Col >= len(rows[id.Row]) {
+		label.SetText("")
+		return
+	}
+	value := rows[id.Row][id.Col]
+	if value == nil {
+		label.Importance = widget.LowImportance
+		label.TextStyle = fyne.TextStyle{Italic: true}
+	} else {
+		label.Importance = widget.MediumImportance
+		label.TextStyle = fyne.TextStyle{}
+	}
+	label.SetText(service.FormatValue(value))
+}
+
+// updateHeader 列标题显示列名和类型，行标题显示在整个结果中的行号
+func (v *ResultView) updateHeader(id widget.TableCellID, obj fyne.CanvasObject) {
+	label := obj.(*widget.Label)
+	switch {
+	case v.result == nil:
+		label.SetText("")
+	case id.Row < 0 && id.Col >= 0 && id.Col < len(v.result.Columns):
+		col := v.result.Columns[id.Col]
+		if col.Type != "" {
+			label.SetText(fmt.Sprintf("%s (%s)", col.Name, col.Type))
+		} else {
+			label.SetText(col.Name)
+		}
+	case id.Col < 0 && id.Row >= 0:
+		label.SetText(fmt.Sprint(v.page*resultPageSize + id.Row + 1))
+	default:
+		label.SetText("")
+	}
+}
+
+// formatElapsed 耗时在一毫秒以上时精确到毫秒
+func formatElapsed(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"fyne.io/fyne/v2/canvas"
+	"github.com/lowSqlGen/internal/service"
 )
 
 type TableConnection struct {
@@ -9,8 +10,9 @@ type TableConnection struct {
 	targetTable     *TableNode
 	sourceLine      *canvas.Line
 	targetLine      *canvas.Line
-	sourceColumn    string
-	targetColumn    string
+	conditions      []service.JoinCondition // 列比较条件，通过实例ID引用列
+	filters         []*service.Condition    // ON 子句中的字面量条件
+	joinType        service.JoinType
 	connectionLabel *canvas.Text
 }
 
@@ -25,15 +27,24 @@ func NewTableConnectionBuilder() *TableConnectionBuilder {
 	}
 }
 
-func (b *TableConnectionBuilder) SetSource(table *TableNode, column string) *TableConnectionBuilder {
+func (b *TableConnectionBuilder) SetSource(table *TableNode) *TableConnectionBuilder {
 	b.connection.sourceTable = table
-	b.connection.sourceColumn = column
 	return b
 }
 
-func (b *TableConnectionBuilder) SetTarget(table *TableNode, column string) *TableConnectionBuilder {
+func (b *TableConnectionBuilder) SetTarget(table *TableNode) *TableConnectionBuilder {
 	b.connection.targetTable = table
-	b.connection.targetColumn = column
+	return b
+}
+
+func (b *TableConnectionBuilder) SetConditions(conditions []service.JoinCondition, filters []*service.Condition) *TableConnectionBuilder {
+	b.connection.conditions = conditions
+	b.connection.filters = filters
+	return b
+}
+
+func (b *TableConnectionBuilder) SetJoinType(joinType service.JoinType) *TableConnectionBuilder {
+	b.connection.joinType = joinType
 	return b
 }
 
@@ -41,3 +52,16 @@ func (b *TableConnectionBuilder) Build() *TableConnection {
 	// 创建连接线和标签
 	return b.connection
 }
+
+// joinInfo 转换为生成器使用的连接信息
+func (conn *TableConnection) joinInfo() service.JoinInfo {
+	return service.JoinInfo{
+		SourceInstance: conn.sourceTable.id,
+		TargetInstance: conn.targetTable.id,
+		SourceTable:    conn.sourceTable.tableName,
+		TargetTable:    conn.targetTable.tableName,
+		Conditions:     conn.conditions,
+		Filters:        conn.filters,
+		JoinType:       conn.joinType,
+	}
+}
@@ -6,9 +6,14 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
 )
 
 type TableNode struct {
+	id          string // 实例ID，同一张表的多个实例依次为 employee、employee#2...
+	tableName   string // 数据库中的表名
+	database    string // 表所在的数据库
+	aliasEntry  *widget.Entry
 	container   *DraggableContainer
 	rect        *canvas.Rectangle
 	name        *canvas.Text
@@ -17,15 +22,24 @@ type TableNode struct {
 	selected    bool
 	columnsBtn  *widget.Button
 	joinBtn     *widget.Button
+	rootBtn     *widget.Button // 标记为FROM主表
+	exprBtn     *widget.Button // 添加计算列
+	columnsBox  *fyne.Container
 	showColumns bool
 }
 
 type ColumnItem struct {
-	container *fyne.Container
-	name      *widget.Label
-	checkbox  *widget.Check
-	dataType  string
-	comment   string
+	container  *fyne.Container
+	column     string // 列名，计算列为其名称
+	name       *widget.Label
+	badges     *fyne.Container // PK、FK、IDX 标记
+	checkbox   *widget.Check
+	alias      *widget.Entry  // 输出别名
+	expression string         // 计算列的表达式，普通列为空
+	aggregate  *widget.Select // 聚合函数选择
+	sort       *widget.Select // 排序方向
+	priority   *widget.Entry  // 排序优先级
+	meta       model.Column   // 列的类型、约束和注释，计算列为空
 }
 
 // 创建新的表节点的工厂方法
@@ -37,6 +51,8 @@ func NewTableNode(tableName string, columns []string) *TableNode {
 			StrokeColor: color.Black,
 			StrokeWidth: 3,
 		},
+		id:          tableName,
+		tableName:   tableName,
 		name:        canvas.NewText(tableName, color.Black),
 		showColumns: true,
 	}
@@ -3,11 +3,14 @@ package gui
 import (
 	"fmt"
 	"image/color"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/lowSqlGen/internal/component"
 	"github.com/lowSqlGen/internal/model"
@@ -15,17 +18,20 @@ import (
 )
 
 type Canvas struct {
-	container        *DraggableContainer
-	tables           map[string]*TableNode
-	connections      []*TableConnection
-	connecting       *TableNode              // 当前正在建立连接的表
-	connectingColumn string                  // 当前选中的连接列
-	dbService        service.DatabaseService // Change from *service.DatabaseService to service.DatabaseService
-	dbConfig         *model.DatabaseConfig   // Added dbConfig to the Canvas struct
-	content          *fyne.Container         // 添加一个主内容容器
-	layout           *CanvasLayout           // 使用组合而不是继承
-	tempConnection   *TableConnection
-	mainWindow       *MainWindow // Add reference to main window for state access
+	container      *DraggableContainer
+	tables         map[string]*TableNode // 实例ID -> 表节点
+	tableOrder     []string              // 实例的添加顺序
+	rootTable      string                // 用户指定的FROM主表实例，为空时使用第一个实例
+	outputColumns  []columnRef           // 输出列的顺序
+	connections    []*TableConnection
+	connecting     *TableNode              // 当前正在建立连接的表
+	dbService      service.DatabaseService // Change from *service.DatabaseService to service.DatabaseService
+	dbConfig       *model.DatabaseConfig   // Added dbConfig to the Canvas struct
+	content        *fyne.Container         // 添加一个主内容容器
+	layout         *CanvasLayout           // 使用组合而不是继承
+	tempConnection *TableConnection
+	mainWindow     *MainWindow // Add reference to main window for state access
+	observers      []ConnectionObserver
 }
 
 // 创建一个可拖动的容器
@@ -148,8 +154,8 @@ func (c *Canvas) hasCollision(node *TableNode, x, y float32) bool {
 	}
 
 	// 检查与所有其他表的碰撞
-	for name, otherNode := range c.tables {
-		if name == node.name.Text {
+	for _, otherNode := range c.tables {
+		if otherNode == node {
 			continue
 		}
 		otherBounds := c.getTableBounds(otherNode)
@@ -196,7 +202,7 @@ func (c *Canvas) findNonCollidingPosition(node *TableNode, baseX, baseY float32)
 
 // 更新表的位置
 func (c *Canvas) updateTablePosition(node *TableNode) {
-	tableName := node.name.Text
+	tableName := node.id
 
 	// 如果这是第一个表，放在起始位置
 	if len(c.tables) == 1 {
@@ -261,7 +267,7 @@ func (c *Canvas) updateTablePosition(node *TableNode) {
 		node.container.Move(fyne.NewPos(finalX, finalY))
 
 		// 更新深度信息
-		c.layout.tableDepths[tableName] = c.layout.tableDepths[sourceTable.name.Text] + 1
+		c.layout.tableDepths[tableName] = c.layout.tableDepths[sourceTable.id] + 1
 	} else {
 		// 独立表的位置计算保持不变
 		baseX := float32(100 + len(c.tables)*50)
@@ -304,7 +310,6 @@ func (c *Canvas) Clear() {
 	// 取消当前正在进行的连接
 	c.CancelConnection()
 	c.connecting = nil
-	c.connectingColumn = ""
 
 	// 清除表
 	if c.tables != nil {
@@ -315,6 +320,10 @@ func (c *Canvas) Clear() {
 		}
 		c.tables = make(map[string]*TableNode)
 	}
+	c.tableOrder = nil
+	c.rootTable = ""
+	c.outputColumns = nil
+	c.notifyOutputColumnsChanged()
 
 	// 刷新容器
 	if c.content != nil {
@@ -332,27 +341,25 @@ func (c *Canvas) Clear() {
 	}
 }
 
-// GetSelectedColumns 获取选中的列
-func (c *Canvas) GetSelectedColumns(tableName string) []string {
+// GetSelectedColumns 获取某个表实例选中的列
+func (c *Canvas) GetSelectedColumns(instanceID string) []string {
 	var selected []string
-	if node, ok := c.tables[tableName]; ok {
+	if node, ok := c.tables[instanceID]; ok {
 		for _, col := range node.columns {
 			if col.checkbox.Checked {
-				selected = append(selected, col.name.Text)
+				selected = append(selected, col.column)
 			}
 		}
 	}
 	return selected
 }
 
-func (c *Canvas) StartConnection(tableName, columnName string) {
-	if node, ok := c.tables[tableName]; ok {
+func (c *Canvas) StartConnection(instanceID string) {
+	if node, ok := c.tables[instanceID]; ok {
 		c.connecting = node
-		c.connectingColumn = columnName
 
 		c.tempConnection = &TableConnection{
 			sourceTable:     node,
-			sourceColumn:    columnName,
 			sourceLine:      canvas.NewLine(color.NRGBA{R: 0, G: 0, B: 0, A: 255}),
 			targetLine:      canvas.NewLine(color.NRGBA{R: 0, G: 0, B: 0, A: 255}),
 			connectionLabel: canvas.NewText("", color.Black),
@@ -375,32 +382,33 @@ func (c *Canvas) CancelConnection() {
 		c.tempConnection = nil
 	}
 	c.connecting = nil
-	c.connectingColumn = ""
 }
 
-func (c *Canvas) CompleteConnection(targetTableName, targetColumnName string) {
+// CompleteConnection 以给定的ON条件连接到目标表实例
+func (c *Canvas) CompleteConnection(targetInstanceID string, joinType service.JoinType, conditions []service.JoinCondition, filters []*service.Condition) {
 	// 检查画布是否正确初始化
 	if c == nil || c.content == nil || c.content.Objects == nil {
 		return
 	}
 
 	// 检查连接参数
-	if c.connecting == nil || targetTableName == "" || targetColumnName == "" {
+	if c.connecting == nil || targetInstanceID == "" ||
+		(joinType.NeedsCondition() && len(conditions)+len(filters) == 0) {
 		c.CancelConnection()
 		return
 	}
 
 	// Add this block back
-	targetNode, ok := c.tables[targetTableName]
+	targetNode, ok := c.tables[targetInstanceID]
 	if !ok || targetNode == nil || targetNode == c.connecting {
 		c.CancelConnection()
 		return
 	}
 
-	// 检查连接是否已存在
+	// 两个实例之间只保留一个连接
 	for _, conn := range c.connections {
-		if conn.sourceTable == c.connecting && conn.targetTable == targetNode &&
-			conn.sourceColumn == c.connectingColumn && conn.targetColumn == targetColumnName {
+		if (conn.sourceTable == c.connecting && conn.targetTable == targetNode) ||
+			(conn.sourceTable == targetNode && conn.targetTable == c.connecting) {
 			c.CancelConnection()
 			return
 		}
@@ -412,12 +420,12 @@ func (c *Canvas) CompleteConnection(targetTableName, targetColumnName string) {
 	}
 
 	// 创建新的永久连接
-	connection := &TableConnection{
-		sourceTable:  c.connecting,
-		targetTable:  targetNode,
-		sourceColumn: c.connectingColumn,
-		targetColumn: targetColumnName,
-	}
+	connection := NewTableConnectionBuilder().
+		SetSource(c.connecting).
+		SetTarget(targetNode).
+		SetJoinType(joinType).
+		SetConditions(conditions, filters).
+		Build()
 
 	// 创建连接线的视觉元素
 	lineStyle := color.NRGBA{R: 0, G: 0, B: 0, A: 255}
@@ -433,11 +441,7 @@ func (c *Canvas) CompleteConnection(targetTableName, targetColumnName string) {
 	connection.targetLine.StrokeWidth = 3
 
 	// 创建连接说明文本
-	labelText := fmt.Sprintf("%s.%s = %s.%s",
-		c.connecting.name.Text, c.connectingColumn,
-		targetNode.name.Text, targetColumnName)
-
-	connection.connectionLabel = canvas.NewText(labelText, lineStyle)
+	connection.connectionLabel = canvas.NewText(connectionLabelText(connection), lineStyle)
 	if connection.connectionLabel == nil {
 		return
 	}
@@ -451,7 +455,17 @@ func (c *Canvas) CompleteConnection(targetTableName, targetColumnName string) {
 
 		// 更新连接线位置
 		c.updateConnectionPosition(connection)
+		c.notifyObservers(connection)
+	}
+}
+
+// connectionLabelText 生成连接线上显示的连接类型和条件
+func connectionLabelText(conn *TableConnection) string {
+	summary := conn.joinInfo().Summary()
+	if summary == "" {
+		return string(conn.joinType)
 	}
+	return fmt.Sprintf("%s: %s", conn.joinType, summary)
 }
 
 func (c *Canvas) updateConnectionPosition(conn *TableConnection) {
@@ -507,30 +521,17 @@ func (c *Canvas) updateConnectionsForTable(node *TableNode) {
 	}
 }
 
-// GetMainTable 获取主表（第一个添加的表）
-func (c *Canvas) GetMainTable() string {
-	if len(c.tables) == 0 {
-		return ""
-	}
-	// 返回第一个添加的表
-	for name := range c.tables {
-		return name
-	}
-	return ""
-}
-
-// GetAllSelectedColumns 获取所有表的选中列
-func (c *Canvas) GetAllSelectedColumns() map[string][]string {
-	result := make(map[string][]string)
+// GetTableColumns 获取画布上所有表实例的列信息
+func (c *Canvas) GetTableColumns() map[string][]model.Column {
+	result := make(map[string][]model.Column)
 	for tableName, node := range c.tables {
-		var selectedColumns []string
 		for _, col := range node.columns {
-			if col.checkbox.Checked {
-				selectedColumns = append(selectedColumns, col.name.Text)
+			if col.expression != "" {
+				continue
 			}
-		}
-		if len(selectedColumns) > 0 {
-			result[tableName] = selectedColumns
+			column := col.meta
+			column.Selected = col.checkbox.Checked
+			result[tableName] = append(result[tableName], column)
 		}
 	}
 	return result
@@ -540,12 +541,7 @@ func (c *Canvas) GetAllSelectedColumns() map[string][]string {
 func (c *Canvas) GetAllJoins() []service.JoinInfo {
 	var joins []service.JoinInfo
 	for _, conn := range c.connections {
-		joins = append(joins, service.JoinInfo{
-			SourceTable:  conn.sourceTable.name.Text,
-			TargetTable:  conn.targetTable.name.Text,
-			SourceColumn: conn.sourceColumn,
-			TargetColumn: conn.targetColumn,
-		})
+		joins = append(joins, conn.joinInfo())
 	}
 	return joins
 }
@@ -586,21 +582,27 @@ func (c *Canvas) updateTableDisplay(node *TableNode) {
 	c.updateConnectionsForTable(node) // 更新连接线位置
 }
 
-// AddTable 添加一个新的表到画布
-func (c *Canvas) AddTable(tableName string, columns []string) {
+// AddTable 添加一个新的表实例到画布，同一张表可以多次添加，返回实例ID
+func (c *Canvas) AddTable(database, tableName string, columns []model.Column) string {
+	return c.addTableInstance(database, tableName, c.nextInstanceID(tableName), columns)
+}
+
+// addTableInstance 以指定的实例ID添加表，ID为空或已被占用时返回空字符串
+func (c *Canvas) addTableInstance(database, tableName, instanceID string, columns []model.Column) string {
 	// Validate required services are available
 	if c.dbService == nil {
 		dialog.ShowError(fmt.Errorf("Database service not initialized"), fyne.CurrentApp().Driver().AllWindows()[0])
-		return
+		return ""
 	}
-
-	// Check if table already exists
-	if _, exists := c.tables[tableName]; exists {
-		return
+	if _, exists := c.tables[instanceID]; exists || instanceID == "" {
+		return ""
 	}
 
 	// Create table node with proper service references
 	node := &TableNode{
+		id:        instanceID,
+		tableName: tableName,
+		database:  database,
 		container: NewDraggableContainer(),
 		rect: &canvas.Rectangle{
 			FillColor:   color.NRGBA{R: 240, G: 240, B: 240, A: 255},
@@ -609,7 +611,6 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 		},
 		name:        canvas.NewText(tableName, color.Black),
 		showColumns: true,
-		dbService:   c.dbService, // Pass database service reference
 	}
 
 	// 设置表格容器大小
@@ -625,9 +626,10 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 	node.name.Move(fyne.NewPos(padding, padding))
 	node.name.Resize(fyne.NewSize(tableWidth-2*padding, headerHeight))
 
-	// 创建列项
-	for _, colName := range columns {
-		columnItem := createColumnItem(colName, c, tableName)
+	// 创建列项，标出主键、外键和索引列
+	keys := c.columnKeys(database, tableName)
+	for _, col := range columns {
+		columnItem := createColumnItem(col, keys[col.Name], c, node)
 		node.columns = append(node.columns, columnItem)
 	}
 
@@ -643,45 +645,64 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 			fyne.CurrentApp().Driver().AllWindows()[0],
 			tableName,
 			c.dbService,
-			c.dbConfig.CurrentDB,
+			database,
 		)
 
-		joinDialog.SetOnConfirm(func(targetTable, sourceColumn, targetColumn string) {
-			// 获取目标表的列
-			columns, err := c.dbService.GetColumns(c.dbConfig.CurrentDB, targetTable)
+		joinDialog.SetOnConfirm(func(selection *JoinSelection) {
+			// 获取目标表的列，目标表与源表在同一个数据库中
+			columns, err := c.dbService.GetColumns(database, selection.TargetTable)
 			if err != nil {
 				dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 				return
 			}
 
 			// 创建连接
-			c.StartConnection(tableName, sourceColumn)
-			// 添加目标表
-			c.AddTable(targetTable, columns)
-			c.CompleteConnection(targetTable, targetColumn)
+			c.StartConnection(instanceID)
+			// 添加目标表的新实例，允许自连接
+			targetID := c.AddTable(database, selection.TargetTable, columns)
+			conditions, filters := selection.Conditions(instanceID, targetID)
+			c.CompleteConnection(targetID, selection.JoinType, conditions, filters)
 		})
 
 		joinDialog.Show()
 	})
 
+	node.rootBtn = widget.NewButton("FROM", func() {
+		c.SetRootTable(instanceID)
+	})
+
+	node.exprBtn = widget.NewButton("ƒx", func() {
+		exprDialog := NewExpressionDialog(fyne.CurrentApp().Driver().AllWindows()[0], node.physicalColumns())
+		exprDialog.SetOnConfirm(func(name, expression string) {
+			c.AddExpressionColumn(instanceID, name, expression)
+		})
+		exprDialog.Show()
+	})
+
 	// 创建按钮容器
-	buttonsContainer := container.NewHBox(node.columnsBtn, node.joinBtn)
+	buttonsContainer := container.NewHBox(node.columnsBtn, node.joinBtn, node.rootBtn, node.exprBtn)
 
-	// 创建表头容器（包含表名和按钮）
+	// 实例别名，为空时自动生成
+	node.aliasEntry = widget.NewEntry()
+	node.aliasEntry.SetPlaceHolder("alias")
+	node.aliasEntry.OnChanged = func(string) { c.notifyObservers(nil) }
+
+	// 创建表头容器（包含实例名、别名和按钮）
 	headerContainer := container.NewHBox(
-		widget.NewLabel(tableName),
+		widget.NewLabel(instanceID),
+		node.aliasEntry,
 		buttonsContainer,
 	)
 
 	// 创建列容器
-	columnsContainer := container.NewVBox()
+	node.columnsBox = container.NewVBox()
 	for _, col := range node.columns {
-		columnsContainer.Add(col.container)
+		node.columnsBox.Add(col.container)
 	}
-	columnsPadded := container.NewPadded(columnsContainer)
+	columnsPadded := container.NewPadded(node.columnsBox)
 
 	// 创建堆叠容器（矩形和列）
-	stackContainer = container.NewStack(
+	stackContainer := container.NewStack(
 		node.rect,
 		container.NewPadded(
 			container.NewVBox(
@@ -692,7 +713,7 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 	)
 
 	// 创建主容器
-	mainContainer = container.NewVBox(
+	mainContainer := container.NewVBox(
 		headerContainer,
 		stackContainer,
 	)
@@ -706,7 +727,9 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 
 	// 添加到画布中
 	c.content.Add(node.container)
-	c.tables[tableName] = node
+	c.tables[instanceID] = node
+	c.tableOrder = append(c.tableOrder, instanceID)
+	c.updateRootButtons()
 
 	// 更新表的位置
 	c.updateTablePosition(node)
@@ -722,43 +745,173 @@ func (c *Canvas) AddTable(tableName string, columns []string) {
 			window.Canvas().Refresh(c.container)
 		}
 	}
-}
 
-func createColumnItem(name string, canvas *Canvas, tableName string) *ColumnItem {
-	// 创建基本标签
-	fullInfo := name
-	var dataType, comment string
+	c.notifyObservers(nil)
+	return instanceID
+}
 
-	// 安全地获取列信息
-	if canvas != nil && canvas.dbService != nil {
-		dataType = canvas.dbService.GetColumnType(tableName, name)
-		if dataType != "" {
-			fullInfo += " " + dataType
+// nextInstanceID 第一个实例使用表名，之后的实例依次追加 #2、#3...
+func (c *Canvas) nextInstanceID(tableName string) string {
+	instanceID := tableName
+	for n := 2; ; n++ {
+		if _, exists := c.tables[instanceID]; !exists {
+			return instanceID
 		}
+		instanceID = fmt.Sprintf("%s#%d", tableName, n)
+	}
+}
 
-		comment = canvas.dbService.GetColumnComment(tableName, name)
-		if comment != "" {
-			fullInfo += fmt.Sprintf(" // %s", comment)
-		}
+func createColumnItem(col model.Column, keys service.ColumnKeys, canvas *Canvas, node *TableNode) *ColumnItem {
+	item := buildColumnItem(col.Name, columnInfoText(col), canvas, node.id)
+	item.meta = col
+	item.badges.Objects = keyBadges(keys)
+	return item
+}
+
+// columnKeys 查询表的索引和外键，查询失败时不显示标记
+func (c *Canvas) columnKeys(database, tableName string) map[string]service.ColumnKeys {
+	indexes, _ := c.dbService.GetIndexes(database, tableName)
+	foreignKeys, _ := c.dbService.GetForeignKeys(database, tableName)
+	return service.TableColumnKeys(tableName, indexes, foreignKeys)
+}
+
+// 列标记的颜色
+var (
+	primaryKeyColor = color.NRGBA{R: 190, G: 140, B: 0, A: 255}
+	foreignKeyColor = color.NRGBA{R: 30, G: 100, B: 200, A: 255}
+	indexColor      = color.NRGBA{R: 40, G: 140, B: 60, A: 255}
+)
+
+// keyBadges 按列的作用创建 PK、FK、IDX 标记
+func keyBadges(keys service.ColumnKeys) []fyne.CanvasObject {
+	var badges []fyne.CanvasObject
+	add := func(text string, c color.Color) {
+		badge := canvas.NewText(text, c)
+		badge.TextStyle = fyne.TextStyle{Bold: true}
+		badge.TextSize = theme.CaptionTextSize()
+		badges = append(badges, badge)
+	}
+	if keys.Primary {
+		add("PK", primaryKeyColor)
 	}
+	if keys.Foreign {
+		add("FK", foreignKeyColor)
+	}
+	if keys.Indexed {
+		add("IDX", indexColor)
+	}
+	return badges
+}
 
-	label := widget.NewLabel(fullInfo)
-	checkbox := widget.NewCheck("", nil)
+// columnInfoText 列的说明文字：名称、类型、可空性、默认值和注释
+func columnInfoText(col model.Column) string {
+	text := col.Name
+	if col.Type != "" {
+		text += " " + col.Type
+	}
+	if !col.Nullable {
+		text += " NOT NULL"
+	}
+	if col.HasDefault {
+		text += fmt.Sprintf(" DEFAULT '%s'", col.Default)
+	}
+	if col.Extra != "" {
+		text += " " + col.Extra
+	}
+	if col.Comment != "" {
+		text += fmt.Sprintf(" // %s", col.Comment)
+	}
+	return text
+}
+
+// createExpressionColumnItem 创建计算列
+func createExpressionColumnItem(name, expression string, canvas *Canvas, instanceID string) *ColumnItem {
+	item := buildColumnItem(name, fmt.Sprintf("ƒx %s = %s", name, expression), canvas, instanceID)
+	item.expression = expression
+	return item
+}
 
+// buildColumnItem 创建列的界面元素：勾选框、说明、别名、聚合和排序设置
+func buildColumnItem(name, text string, canvas *Canvas, instanceID string) *ColumnItem {
+	label := widget.NewLabel(text)
+	checkbox := widget.NewCheck("", func(checked bool) {
+		if canvas != nil {
+			canvas.setColumnOutput(instanceID, name, checked)
+		}
+	})
+	alias := widget.NewEntry()
+	alias.SetPlaceHolder("AS")
+	alias.OnChanged = func(string) { canvas.notifyObservers(nil) }
+
+	// 聚合函数选择，默认不聚合
+	aggregateOptions := []string{noAggregate}
+	for _, agg := range service.AggregateFuncs() {
+		aggregateOptions = append(aggregateOptions, string(agg))
+	}
+	aggregate := widget.NewSelect(aggregateOptions, nil)
+	aggregate.SetSelected(noAggregate)
+
+	// 排序方向和优先级
+	sortSelect := widget.NewSelect([]string{noSort, string(service.SortAsc), string(service.SortDesc)}, nil)
+	sortSelect.SetSelected(noSort)
+	priority := widget.NewEntry()
+	priority.SetPlaceHolder("#")
+
+	// 设置变化时通知观察者重新生成语句
+	changed := func(string) { canvas.notifyObservers(nil) }
+	aggregate.OnChanged = changed
+	sortSelect.OnChanged = changed
+	priority.OnChanged = changed
+
+	badges := container.NewHBox()
 	container := container.NewPadded( // 添加内边距
 		container.NewHBox(
 			checkbox,
+			container.NewCenter(badges),
 			label,
+			alias,
+			aggregate,
+			sortSelect,
+			priority,
 		),
 	)
 
 	return &ColumnItem{
 		container: container,
+		column:    name,
 		name:      label,
+		badges:    badges,
 		checkbox:  checkbox,
-		dataType:  dataType,
-		comment:   comment,
+		alias:     alias,
+		aggregate: aggregate,
+		sort:      sortSelect,
+		priority:  priority,
+	}
+}
+
+const (
+	noAggregate = "-" // 聚合选择框中表示不聚合的选项
+	noSort      = "-" // 排序选择框中表示不排序的选项
+)
+
+// aggregateFunc 返回列选择的聚合函数
+func (col *ColumnItem) aggregateFunc() service.AggregateFunc {
+	if col.aggregate == nil || col.aggregate.Selected == noAggregate {
+		return service.AggNone
+	}
+	return service.AggregateFunc(col.aggregate.Selected)
+}
+
+// sortOrder 返回列的排序方向和优先级，优先级未填写或无效时为 0
+func (col *ColumnItem) sortOrder() (service.SortDirection, int) {
+	if col.sort == nil || col.sort.Selected == noSort {
+		return service.SortNone, 0
 	}
+	priority, err := strconv.Atoi(strings.TrimSpace(col.priority.Text))
+	if err != nil {
+		priority = 0
+	}
+	return service.SortDirection(col.sort.Selected), priority
 }
 
 func NewCanvas(dbService service.DatabaseService, dbConfig *model.DatabaseConfig, mainWindow *MainWindow) *Canvas {
@@ -772,7 +925,7 @@ func NewCanvas(dbService service.DatabaseService, dbConfig *model.DatabaseConfig
 		dbConfig:    dbConfig,
 		connections: make([]*TableConnection, 0),
 		content:     container.NewWithoutLayout(),
-		layout:      &CanvasLayout{
+		layout: &CanvasLayout{
 			tableDepths: make(map[string]int),
 			tableRows:   make(map[string]int),
 		},
@@ -803,9 +956,20 @@ func NewCanvas(dbService service.DatabaseService, dbConfig *model.DatabaseConfig
 
 // 使用观察者模式处理连接状态变化
 type ConnectionObserver interface {
+	// OnConnectionChanged 在建立连接后调用；表、列等其他设置变化时 conn 为 nil
 	OnConnectionChanged(conn *TableConnection)
 }
 
 func (c *Canvas) AddConnectionObserver(observer ConnectionObserver) {
-	// ... 添加观察者
+	c.observers = append(c.observers, observer)
+}
+
+// notifyObservers 通知所有观察者画布发生了变化
+func (c *Canvas) notifyObservers(conn *TableConnection) {
+	if c == nil {
+		return
+	}
+	for _, observer := range c.observers {
+		observer.OnConnectionChanged(conn)
+	}
 }
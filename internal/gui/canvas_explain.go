@@ -0,0 +1,33 @@
+package gui
+
+import (
+	"image/color"
+
+	"github.com/lowSqlGen/internal/service"
+)
+
+// 执行计划问题在表节点上的颜色
+var (
+	fullScanColor = color.NRGBA{R: 230, G: 140, B: 0, A: 255}
+	noIndexColor  = color.NRGBA{R: 200, G: 0, B: 0, A: 255}
+)
+
+// ShowPlanWarnings 按执行计划标记表节点，steps 为实例ID到访问该实例的步骤，传入 nil 清除标记
+func (c *Canvas) ShowPlanWarnings(steps map[string]*service.PlanNode) {
+	for id, node := range c.tables {
+		var stroke color.Color = color.Black
+		text := node.tableName
+		if step, ok := steps[id]; ok && step.Warning() != "" {
+			stroke = fullScanColor
+			if step.NoIndex {
+				stroke = noIndexColor
+			}
+			text += ": " + step.Warning()
+		}
+		node.rect.StrokeColor = stroke
+		node.rect.Refresh()
+		node.name.Text = text
+		node.name.Color = stroke
+		node.name.Refresh()
+	}
+}
@@ -0,0 +1,40 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// physicalColumns 返回表的实际列名（不含计算列）
+func (node *TableNode) physicalColumns() []string {
+	var names []string
+	for _, col := range node.columns {
+		if col.expression == "" {
+			names = append(names, col.column)
+		}
+	}
+	return names
+}
+
+// AddExpressionColumn 为表实例添加一个计算列，名称在表内必须唯一
+func (c *Canvas) AddExpressionColumn(instanceID, name, expression string) {
+	node, ok := c.tables[instanceID]
+	if !ok {
+		return
+	}
+	if c.findColumn(instanceID, name) != nil {
+		dialog.ShowError(fmt.Errorf("Column %s already exists in %s", name, instanceID),
+			fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+
+	item := createExpressionColumnItem(name, expression, c, instanceID)
+	node.columns = append(node.columns, item)
+	node.columnsBox.Add(item.container)
+
+	// 计算列默认输出
+	item.checkbox.SetChecked(true)
+	c.updateTableDisplay(node)
+}
@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// ImportQuery 清空画布并按导入的语句重建表实例、连线和输出列。
+// 画布与导入结果使用相同的实例ID规则，清空后按顺序添加即得到相同的ID，
+// 重建前已存在的实例保持原来的位置。导入的表都属于 database，columnsOf 从该数据库读取列
+func (c *Canvas) ImportQuery(q *service.ImportedQuery, database string, columnsOf service.ColumnLookup) error {
+	positions := make(map[string]fyne.Position)
+	for instanceID, node := range c.tables {
+		positions[instanceID] = node.container.Position()
+	}
+	c.Clear()
+
+	for _, table := range q.Tables {
+		columns, err := columnsOf(table.Table)
+		if err != nil {
+			return err
+		}
+
+		// 与连接对话框的流程一致：先从源表开始连接，再添加目标表
+		join := importedJoin(q.Joins, table.ID)
+		if join != nil {
+			c.StartConnection(join.SourceInstance)
+		}
+		instanceID := c.AddTable(database, table.Table, columns)
+		if instanceID != table.ID {
+			c.CancelConnection()
+			return fmt.Errorf("Failed to add table %s", table.ID)
+		}
+		node := c.tables[instanceID]
+		node.aliasEntry.SetText(table.Alias)
+		if pos, ok := positions[instanceID]; ok {
+			node.container.Move(pos)
+		}
+		if join != nil {
+			c.CompleteConnection(instanceID, join.JoinType, join.Conditions, join.Filters)
+		}
+	}
+	if len(q.Tables) > 0 {
+		c.SetRootTable(q.Tables[0].ID)
+	}
+	c.updateAllConnections()
+
+	// 按输出顺序勾选列，计算列添加后默认勾选
+	for _, col := range q.Columns {
+		if col.Expression != "" {
+			c.AddExpressionColumn(col.Table, col.Name, col.Expression)
+		}
+		item := c.findColumn(col.Table, col.Name)
+		if item == nil {
+			continue
+		}
+		item.checkbox.SetChecked(true)
+		item.alias.SetText(col.Alias)
+		if col.Aggregate != service.AggNone {
+			item.aggregate.SetSelected(string(col.Aggregate))
+		}
+		if col.Sort != service.SortNone {
+			item.sort.SetSelected(string(col.Sort))
+			item.priority.SetText(strconv.Itoa(col.SortPriority))
+		}
+	}
+	return nil
+}
+
+// importedJoin 查找以指定实例为目标的连接
+func importedJoin(joins []service.JoinInfo, targetID string) *service.JoinInfo {
+	for i := range joins {
+		if joins[i].TargetInstance == targetID {
+			return &joins[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// columnRef 指向画布上某个表实例的某一列
+type columnRef struct {
+	table  string // 实例ID
+	column string
+}
+
+// GetMainTable 获取主表，未指定时返回第一个添加的表
+func (c *Canvas) GetMainTable() string {
+	if _, ok := c.tables[c.rootTable]; ok {
+		return c.rootTable
+	}
+	if len(c.tableOrder) == 0 {
+		return ""
+	}
+	return c.tableOrder[0]
+}
+
+// SetRootTable 指定FROM子句中的主表实例
+func (c *Canvas) SetRootTable(instanceID string) {
+	if _, ok := c.tables[instanceID]; !ok {
+		return
+	}
+	c.rootTable = instanceID
+	c.updateRootButtons()
+	c.notifyObservers(nil)
+}
+
+// updateRootButtons 高亮当前主表的FROM按钮
+func (c *Canvas) updateRootButtons() {
+	mainTable := c.GetMainTable()
+	for instanceID, node := range c.tables {
+		if node.rootBtn == nil {
+			continue
+		}
+		if instanceID == mainTable {
+			node.rootBtn.Importance = widget.HighImportance
+		} else {
+			node.rootBtn.Importance = widget.MediumImportance
+		}
+		node.rootBtn.Refresh()
+	}
+}
+
+// setColumnOutput 勾选列时追加到输出列末尾，取消勾选时移除
+func (c *Canvas) setColumnOutput(instanceID, columnName string, checked bool) {
+	ref := columnRef{table: instanceID, column: columnName}
+	for i, existing := range c.outputColumns {
+		if existing == ref {
+			if !checked {
+				c.outputColumns = append(c.outputColumns[:i], c.outputColumns[i+1:]...)
+				c.notifyOutputColumnsChanged()
+			}
+			return
+		}
+	}
+	if checked {
+		c.outputColumns = append(c.outputColumns, ref)
+		c.notifyOutputColumnsChanged()
+	}
+}
+
+// MoveOutputColumn 调整输出列的顺序
+func (c *Canvas) MoveOutputColumn(from, to int) {
+	if from < 0 || from >= len(c.outputColumns) || to < 0 || to >= len(c.outputColumns) {
+		return
+	}
+	ref := c.outputColumns[from]
+	c.outputColumns = append(c.outputColumns[:from], c.outputColumns[from+1:]...)
+	c.outputColumns = append(c.outputColumns[:to], append([]columnRef{ref}, c.outputColumns[to:]...)...)
+	c.notifyOutputColumnsChanged()
+}
+
+// GetOutputColumnLabels 获取输出列的显示文本
+func (c *Canvas) GetOutputColumnLabels() []string {
+	var labels []string
+	for _, ref := range c.outputColumns {
+		labels = append(labels, fmt.Sprintf("%s.%s", ref.table, ref.column))
+	}
+	return labels
+}
+
+// GetTableInstances 按添加顺序获取画布上的表实例及其别名
+func (c *Canvas) GetTableInstances() []service.TableInstance {
+	var instances []service.TableInstance
+	for _, instanceID := range c.tableOrder {
+		node := c.tables[instanceID]
+		instances = append(instances, service.TableInstance{
+			ID:       instanceID,
+			Table:    node.tableName,
+			Alias:    strings.TrimSpace(node.aliasEntry.Text),
+			Database: node.database,
+		})
+	}
+	return instances
+}
+
+// GetOutputColumns 按输出顺序获取选中的列及其聚合、排序设置
+func (c *Canvas) GetOutputColumns() []service.SelectColumn {
+	var result []service.SelectColumn
+	for _, ref := range c.outputColumns {
+		col := c.findColumn(ref.table, ref.column)
+		if col == nil {
+			continue
+		}
+		direction, priority := col.sortOrder()
+		result = append(result, service.SelectColumn{
+			Table:        ref.table,
+			Name:         ref.column,
+			Alias:        strings.TrimSpace(col.alias.Text),
+			Expression:   col.expression,
+			Aggregate:    col.aggregateFunc(),
+			Sort:         direction,
+			SortPriority: priority,
+		})
+	}
+	return result
+}
+
+// findColumn 查找画布上某个表实例的列
+func (c *Canvas) findColumn(instanceID, columnName string) *ColumnItem {
+	node, ok := c.tables[instanceID]
+	if !ok {
+		return nil
+	}
+	for _, col := range node.columns {
+		if col.column == columnName {
+			return col
+		}
+	}
+	return nil
+}
+
+// notifyOutputColumnsChanged 通知主窗口刷新输出列列表
+func (c *Canvas) notifyOutputColumnsChanged() {
+	if c.mainWindow != nil {
+		c.mainWindow.refreshOutputColumns()
+	}
+	c.notifyObservers(nil)
+}
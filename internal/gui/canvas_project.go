@@ -0,0 +1,133 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// FillProject 把画布上的表实例、列设置、连线和输出顺序写入项目
+func (c *Canvas) FillProject(p *service.Project) {
+	p.RootTable = c.rootTable
+	for _, instance := range c.GetTableInstances() {
+		node := c.tables[instance.ID]
+		pos := node.container.Position()
+		table := service.ProjectTable{TableInstance: instance, X: pos.X, Y: pos.Y, ShowColumns: node.showColumns}
+		for _, col := range node.columns {
+			direction, priority := col.sortOrder()
+			saved := service.ProjectColumn{
+				Name:         col.column,
+				Expression:   col.expression,
+				Checked:      col.checkbox.Checked,
+				Alias:        strings.TrimSpace(col.alias.Text),
+				Aggregate:    col.aggregateFunc(),
+				Sort:         direction,
+				SortPriority: priority,
+			}
+			// 未改动的普通列不保存
+			if saved != (service.ProjectColumn{Name: col.column}) {
+				table.Columns = append(table.Columns, saved)
+			}
+		}
+		p.Tables = append(p.Tables, table)
+	}
+
+	for _, conn := range c.connections {
+		join := conn.joinInfo()
+		p.Joins = append(p.Joins, service.ProjectJoin{
+			Source:     join.SourceInstance,
+			Target:     join.TargetInstance,
+			JoinType:   join.JoinType,
+			Conditions: join.Conditions,
+			Filters:    join.Filters,
+		})
+	}
+	for _, ref := range c.outputColumns {
+		p.Output = append(p.Output, service.ColumnRef{Instance: ref.table, Column: ref.column})
+	}
+}
+
+// LoadProject 清空画布并按项目重建，返回无法还原的部分，例如数据库中已经删除的列。
+// 未记录数据库的表（旧版本保存的项目）属于项目的数据库
+func (c *Canvas) LoadProject(p *service.Project) ([]string, error) {
+	c.Clear()
+
+	for _, table := range p.Tables {
+		database := table.Database
+		if database == "" {
+			database = p.Database
+		}
+		columns, err := c.dbService.GetColumns(database, table.Table)
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("Table %s does not exist in database %s", table.Table, database)
+		}
+		// 使用保存的实例ID，连线和输出列都通过它引用表实例
+		instanceID := c.addTableInstance(database, table.Table, table.ID, columns)
+		if instanceID == "" {
+			return nil, fmt.Errorf("Failed to restore table %s", table.ID)
+		}
+		node := c.tables[instanceID]
+		node.aliasEntry.SetText(table.Alias)
+		node.container.Move(fyne.NewPos(table.X, table.Y))
+		node.showColumns = table.ShowColumns
+		c.updateTableDisplay(node)
+		for _, col := range table.Columns {
+			if col.Expression != "" {
+				c.AddExpressionColumn(instanceID, col.Name, col.Expression)
+			}
+		}
+	}
+	// 计算列添加后默认勾选，先全部取消，再按保存的输出顺序勾选
+	for _, ref := range append([]columnRef(nil), c.outputColumns...) {
+		c.findColumn(ref.table, ref.column).checkbox.SetChecked(false)
+	}
+
+	var warnings []string
+	for _, join := range p.Joins {
+		count := len(c.connections)
+		c.StartConnection(join.Source)
+		c.CompleteConnection(join.Target, join.JoinType, join.Conditions, join.Filters)
+		if len(c.connections) == count {
+			warnings = append(warnings, fmt.Sprintf("Join %s -> %s could not be restored", join.Source, join.Target))
+		}
+	}
+	for _, ref := range p.Output {
+		if col := c.findColumn(ref.Instance, ref.Column); col != nil {
+			col.checkbox.SetChecked(true)
+		}
+	}
+	for _, table := range p.Tables {
+		for _, saved := range table.Columns {
+			col := c.findColumn(table.ID, saved.Name)
+			if col == nil {
+				warnings = append(warnings, fmt.Sprintf("Column %s.%s no longer exists", table.ID, saved.Name))
+				continue
+			}
+			restoreColumnSettings(col, saved)
+		}
+	}
+
+	if p.RootTable != "" {
+		c.SetRootTable(p.RootTable)
+	}
+	c.updateAllConnections()
+	return warnings, nil
+}
+
+// restoreColumnSettings 恢复列的别名、聚合和排序设置
+func restoreColumnSettings(col *ColumnItem, saved service.ProjectColumn) {
+	col.alias.SetText(saved.Alias)
+	if saved.Aggregate != service.AggNone {
+		col.aggregate.SetSelected(string(saved.Aggregate))
+	}
+	if saved.Sort != service.SortNone {
+		col.sort.SetSelected(string(saved.Sort))
+		col.priority.SetText(strconv.Itoa(saved.SortPriority))
+	}
+}
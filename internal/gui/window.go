@@ -2,7 +2,9 @@ package gui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -13,126 +15,68 @@ import (
 )
 
 type MainWindow struct {
-	window    fyne.Window
-	canvas    *Canvas
-	leftBar   *widget.Tree
-	rightBar  *widget.Entry
-	dbConfig  *model.DatabaseConfig
-	dbService service.DatabaseService
-	dbTables  map[string][]string
-	firstTable       bool
+	window            fyne.Window
+	canvas            *Canvas
+	leftBar           *widget.Tree
+	rightBar          *widget.Entry
+	syncLabel         *widget.Label // 预览与画布的同步状态
+	sync              previewSync
+	distinctCheck     *widget.Check
+	limitEntry        *widget.Entry
+	offsetEntry       *widget.Entry
+	dialectSelect     *widget.Select // 生成语句使用的SQL方言
+	filterPanel       *FilterPanel
+	havingPanel       *FilterPanel
+	outputList        *OutputColumnList
+	designArea        *fyne.Container    // 画布所在的容器，重新连接时替换画布
+	results           *ResultView        // 执行查询的结果
+	explain           *ExplainView       // 预览语句的执行计划
+	resultTabs        *container.AppTabs // 画布下方的结果标签页
+	rowLimitSelect    *widget.Select     // 执行查询时最多读取的行数
+	dbConfig          *model.DatabaseConfig
+	profiles          *service.ProfileStore // 保存的连接配置，读取失败时为 nil
+	dbService         service.DatabaseService
+	schemaCache       *service.CachedDatabaseService            // dbService 的缓存层，用于刷新表结构
+	dbObjects         map[string][]model.SchemaObject           // 数据库 -> 表、视图等对象
+	schemaNodes       map[widget.TreeNodeID]schemaNode          // 数据库树中的目录和对象节点
+	schemaChildren    map[widget.TreeNodeID][]widget.TreeNodeID // 数据库和目录节点的子节点
+	firstTable        bool
 	currentAddedTable string
+	projectPath       string // 当前打开的项目文件，未保存过时为空
+	baseTitle         string // 不含项目文件名的窗口标题
 }
 
 func InitMainWindow(window fyne.Window) *MainWindow {
 	mainWindow := &MainWindow{
-		window:           window,
-		rightBar:         widget.NewEntry(),
-		dbTables:        make(map[string][]string),
-		firstTable:      true,  // Initialize state
+		window:            window,
+		rightBar:          widget.NewEntry(),
+		syncLabel:         &widget.Label{Wrapping: fyne.TextWrapWord},
+		firstTable:        true, // Initialize state
 		currentAddedTable: "",
+		baseTitle:         window.Title(),
 	}
 
+	mainWindow.resetSchemaObjects()
+	mainWindow.loadProfiles()
+
+	// 输出列列表，拖动调整顺序
+	mainWindow.outputList = NewOutputColumnList(func(from, to int) {
+		mainWindow.canvas.MoveOutputColumn(from, to)
+	})
+
 	// Remove local state variables and use struct fields instead
 	mainWindow.canvas = NewCanvas(nil, nil, mainWindow) // Pass mainWindow for state access
+	mainWindow.canvas.AddConnectionObserver(mainWindow)
 
-	// 修改树形结构的创建
-	mainWindow.leftBar = widget.NewTree(
-		func(id widget.TreeNodeID) []widget.TreeNodeID {
-			if id == "" {
-				// root节点返回所有数据库
-				return mainWindow.getDatabases()
-			}
-			// 数据库节点返回其包含的表
-			if tables, ok := mainWindow.dbTables[id]; ok {
-				var nodeIDs []widget.TreeNodeID
-				for _, table := range tables {
-					nodeIDs = append(nodeIDs, id+"/"+table)
-				}
-				return nodeIDs
-			}
-			return nil
-		},
-		func(id widget.TreeNodeID) bool {
-			// 如果是数据库节点则返回true
-			return !strings.Contains(id, "/")
-		},
-		func(branch bool) fyne.CanvasObject {
-			if branch {
-				return widget.NewLabel("Template")
-			}
-			// 为表节点创建一个容器，包含标签和按钮
-			label := widget.NewLabel("Template")
-			btn := widget.NewButton("Add", nil)
-			return container.NewBorder(nil, nil, nil, btn, label)
-		},
-		func(id widget.TreeNodeID, branch bool, node fyne.CanvasObject) {
-			if branch {
-				label := node.(*widget.Label)
-				label.SetText(id)
-				return
-			}
-
-			// 表节点
-			cont := node.(*fyne.Container)
-			label := cont.Objects[0].(*widget.Label)
-			btn := cont.Objects[1].(*widget.Button)
-
-			parts := strings.Split(id, "/")
-			dbName := parts[0]
-			tableName := parts[1]
-
-			// 设置表名和注释
-			if mainWindow.dbService != nil {
-				comment := mainWindow.dbService.GetTableComment(dbName, tableName)
-				if comment != "" {
-					label.SetText(fmt.Sprintf("%s // %s", tableName, comment))
-				} else {
-					label.SetText(tableName)
-				}
-			} else {
-				label.SetText(tableName)
-			}
-
-			// 根据状态设置按钮
-			if mainWindow.currentAddedTable == tableName {
-				btn.SetText("Cancel")
-				btn.OnTapped = func() {
-					mainWindow.canvas.Clear()
-					mainWindow.currentAddedTable = ""
-					mainWindow.firstTable = true
-					mainWindow.leftBar.Refresh()
-				}
-			} else {
-				btn.SetText("Add")
-				if mainWindow.firstTable || mainWindow.currentAddedTable == "" {
-					btn.Show()
-				} else {
-					btn.Hide()
-				}
-				btn.OnTapped = func() {
-					columns, err := mainWindow.dbService.GetColumns(dbName, tableName)
-					if err != nil {
-						dialog.ShowError(err, mainWindow.window)
-						return
-					}
-					mainWindow.canvas.AddTable(tableName, columns)
-					mainWindow.window.Canvas().Refresh(mainWindow.canvas.container)
-
-					mainWindow.currentAddedTable = tableName
-					mainWindow.firstTable = false
-					mainWindow.leftBar.Refresh()
-				}
-			}
-		},
-	)
+	// 数据库树，按数据库列出表、视图、存储过程和触发器
+	mainWindow.leftBar = mainWindow.newSchemaTree()
 
 	// 删除原来的 OnSelected 事件处理
 	mainWindow.leftBar.OnSelected = nil
 
 	// 创建数据库连接按钮
 	connectBtn := widget.NewButton("Click here to Connect to Database", func() {
-		dialog := NewDBConfigDialog(window)
+		dialog := NewDBConfigDialog(window, mainWindow.profiles)
 		dialog.SetOnSubmit(func(config *model.DatabaseConfig) {
 			mainWindow.dbConfig = config
 			mainWindow.connectToDatabase()
@@ -140,15 +84,80 @@ func InitMainWindow(window fyne.Window) *MainWindow {
 		dialog.Show()
 	})
 
+	// 创建WHERE条件面板，列信息从当前画布实时获取
+	tableColumns := func() map[string][]model.Column {
+		return mainWindow.canvas.GetTableColumns()
+	}
+	mainWindow.filterPanel = NewFilterPanel(window, tableColumns)
+	mainWindow.havingPanel = NewHavingPanel(window, tableColumns)
+	mainWindow.filterPanel.SetOnChanged(mainWindow.refreshPreview)
+	mainWindow.havingPanel.SetOnChanged(mainWindow.refreshPreview)
+
 	// 创建生成SQL按钮
 	generateBtn := widget.NewButton("Generate SQL", func() {
 		mainWindow.generateSQL()
 	})
 
+	// 获取执行计划，在画布上标出全表扫描和缺少索引的表
+	explainBtn := widget.NewButton("Explain", func() {
+		mainWindow.explainQuery()
+	})
+
+	// 从SQL语句还原画布
+	importBtn := widget.NewButton("Import SQL", func() {
+		mainWindow.showImportDialog()
+	})
+
+	// 创建DISTINCT和分页选项
+	mainWindow.distinctCheck = widget.NewCheck("DISTINCT", func(bool) {
+		mainWindow.refreshPreview()
+	})
+	mainWindow.limitEntry = widget.NewEntry()
+	mainWindow.limitEntry.SetPlaceHolder("Limit")
+	mainWindow.limitEntry.OnChanged = func(string) { mainWindow.refreshPreview() }
+	mainWindow.offsetEntry = widget.NewEntry()
+	mainWindow.offsetEntry.SetPlaceHolder("Offset")
+	mainWindow.offsetEntry.OnChanged = func(string) { mainWindow.refreshPreview() }
+
+	// SQL方言选择，连接数据库时切换为对应的方言，修改后重新生成预览
+	var dialectNames []string
+	for _, d := range service.Dialects() {
+		dialectNames = append(dialectNames, d.Name())
+	}
+	mainWindow.dialectSelect = widget.NewSelect(dialectNames, func(string) {
+		mainWindow.refreshPreview()
+	})
+	mainWindow.dialectSelect.SetSelected(service.MySQL.Name())
+
+	// 执行预览中的语句，结果显示在画布下方
+	runBtn := widget.NewButton("Run", func() {
+		mainWindow.runQuery()
+	})
+	// 不限行数，逐行导出全部结果
+	exportBtn := widget.NewButton("Export...", func() {
+		mainWindow.showExportDialog()
+	})
+	mainWindow.rowLimitSelect = widget.NewSelect(rowLimits, nil)
+	mainWindow.rowLimitSelect.SetSelected(rowLimits[1])
+
+	generateBar := container.NewHBox(
+		generateBtn,
+		explainBtn,
+		importBtn,
+		mainWindow.distinctCheck,
+		mainWindow.limitEntry,
+		mainWindow.offsetEntry,
+	)
+
+	// 刷新表结构缓存
+	refreshBtn := widget.NewButton("Refresh", func() {
+		mainWindow.refreshSchemas()
+	})
+
 	// 创建左侧面板
 	leftContainer := container.NewVBox(
 		connectBtn,
-		widget.NewLabel("Databases & Tables"),
+		container.NewBorder(nil, nil, nil, refreshBtn, widget.NewLabel("Databases & Tables")),
 	)
 
 	// 创建一个滚动容器来包装树形结构
@@ -158,25 +167,42 @@ func InitMainWindow(window fyne.Window) *MainWindow {
 	leftContainer.Add(treeScroll)
 
 	// 创建中间画布
-	centerContainer := container.NewVBox(
+	mainWindow.designArea = container.NewVBox(
 		widget.NewLabel("Design Area"),
 		container.NewPadded(mainWindow.canvas.container), // 添加内边距
 	)
 
 	// 设置中间容器的最小大小
-	centerContainer.Resize(fyne.NewSize(800, 600))
+	mainWindow.designArea.Resize(fyne.NewSize(800, 600))
+
+	// 画布下方的查询结果
+	mainWindow.results = NewResultView()
+	mainWindow.explain = NewExplainView()
+	mainWindow.resultTabs = container.NewAppTabs(
+		container.NewTabItem("Results", mainWindow.results.Container()),
+		container.NewTabItem("Explain", mainWindow.explain.Container()),
+	)
+	centerContainer := container.NewVSplit(mainWindow.designArea, mainWindow.resultTabs)
+	centerContainer.SetOffset(0.7)
 
-	// 创建右侧SQL预览
+	// 创建右侧SQL预览，编辑后的语句同步回画布
 	mainWindow.rightBar.MultiLine = true             // 启用多行模式
 	mainWindow.rightBar.Wrapping = fyne.TextWrapWord // 启用自动换行
+	mainWindow.rightBar.OnChanged = mainWindow.onPreviewEdited
 
 	// 创建一个滚动容器来包装SQL预览
 	sqlScroll := container.NewVScroll(mainWindow.rightBar)
 	sqlScroll.SetMinSize(fyne.NewSize(200, 600)) // 设置最小高度
 
 	rightContainer := container.NewVBox(
-		widget.NewLabel("SQL Preview"),
-		generateBtn,
+		container.NewHBox(widget.NewLabel("SQL Preview"), mainWindow.dialectSelect, runBtn, mainWindow.rowLimitSelect, exportBtn),
+		generateBar,
+		widget.NewAccordion(
+			widget.NewAccordionItem("Output Columns", mainWindow.outputList.Container()),
+			widget.NewAccordionItem("Where", mainWindow.filterPanel.Container()),
+			widget.NewAccordionItem("Having", mainWindow.havingPanel.Container()),
+		),
+		mainWindow.syncLabel,
 		sqlScroll, // 使用滚动容器替代直接的文本框
 	)
 
@@ -194,6 +220,7 @@ func InitMainWindow(window fyne.Window) *MainWindow {
 	rightSplit := split.Trailing.(*container.Split)
 	rightSplit.SetOffset(0.7) // 设计区域占70%，SQL预览占30%
 
+	window.SetMainMenu(mainWindow.buildMainMenu())
 	window.SetContent(split)
 	window.Resize(fyne.NewSize(1200, 800)) // 设置更大的默认窗口大小
 
@@ -203,96 +230,225 @@ func InitMainWindow(window fyne.Window) *MainWindow {
 // 获取所有数据库名称
 func (m *MainWindow) getDatabases() []widget.TreeNodeID {
 	var databases []widget.TreeNodeID
-	for db := range m.dbTables {
+	for db := range m.dbObjects {
 		databases = append(databases, widget.TreeNodeID(db))
 	}
 	return databases
 }
 
-func (m *MainWindow) connectToDatabase() {
-	dbService, err := service.NewDatabaseService(m.dbConfig)
+// loadProfiles 读取用户配置目录下保存的连接配置
+func (m *MainWindow) loadProfiles() {
+	path, err := service.DefaultProfilePath()
+	if err == nil {
+		m.profiles, err = service.LoadProfileStore(path)
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Saved connections are unavailable: %v", err), m.window)
+	}
+}
+
+// connectToDatabase 按 dbConfig 连接数据库并重置画布，连接失败时返回 false
+func (m *MainWindow) connectToDatabase() bool {
+	rawService, err := service.NewDatabaseService(m.dbConfig)
 	if err != nil {
 		dialog.ShowError(err, m.window)
-		return
+		return false
+	}
+
+	// 关闭之前的连接，中止仍在执行的查询并清空之前服务器的数据库树
+	if m.dbService != nil {
+		m.results.Cancel()
+		m.dbService.Close()
 	}
+	m.resetSchemaObjects()
+	m.leftBar.Refresh()
+
+	// 表结构经缓存层访问，加载完成后刷新树以显示表注释
+	m.schemaCache = service.NewCachedDatabaseService(rawService)
+	// 回调在后台协程中执行，刷新交给事件协程
+	m.schemaCache.SetOnLoaded(func(string) {
+		m.runOnUI(m.leftBar.Refresh)
+	})
+	m.dbService = m.schemaCache
+
+	// Create new canvas with proper initialization
+	m.canvas = NewCanvas(m.dbService, m.dbConfig, m)
+	m.canvas.AddConnectionObserver(m)
+	m.refreshOutputColumns()
 
-	m.dbService = dbService
 	// Reset state when connecting to new database
+	m.dbConfig.CurrentDB = ""
+	m.setPreview("")
+	m.dialectSelect.SetSelected(service.DialectFor(m.dbConfig.Driver).Name())
 	m.firstTable = true
 	m.currentAddedTable = ""
-	
-	// Create new canvas with proper initialization
-	m.canvas = NewCanvas(dbService, m.dbConfig, m)
+	m.filterPanel.Reset()
+	m.havingPanel.Reset()
 	m.canvas.container.Resize(fyne.NewSize(800, 600))
 
 	// 刷新中间容器
-	if centerContainer, ok := m.window.Content().(*container.Split).Trailing.(*container.Split).Leading.(*fyne.Container); ok {
-		centerContainer.Objects[1] = container.NewPadded(m.canvas.container)
-		centerContainer.Refresh()
-	}
+	m.designArea.Objects[1] = container.NewPadded(m.canvas.container)
+	m.designArea.Refresh()
 
-	// 获取数据库列表
-	databases, err := m.dbService.GetDatabases()
-	if err != nil {
-		dialog.ShowError(err, m.window)
-		return
-	}
+	m.loadSchemas()
+	return true
+}
 
-	// 获取每个数据库的表
-	for _, dbName := range databases {
-		tables, err := m.dbService.GetTables(dbName)
+// loadSchemas 在后台加载所有数据库的表，各数据库并发加载，全部完成后在事件协程中更新数据库树
+func (m *MainWindow) loadSchemas() {
+	dbService, schemaCache := m.dbService, m.schemaCache
+	go func() {
+		// 获取数据库列表
+		databases, err := dbService.GetDatabases()
 		if err != nil {
-			dialog.ShowError(err, m.window)
+			m.runOnUI(func() { dialog.ShowError(err, m.window) })
+			return
+		}
+		schemaCache.Prefetch(databases...)
+
+		// 并发获取每个数据库的表、视图等对象
+		results := make([][]model.SchemaObject, len(databases))
+		errs := make([]error, len(databases))
+		var wg sync.WaitGroup
+		for i, dbName := range databases {
+			wg.Add(1)
+			go func(i int, dbName string) {
+				defer wg.Done()
+				results[i], errs[i] = dbService.GetObjects(dbName)
+			}(i, dbName)
+		}
+		wg.Wait()
+
+		m.runOnUI(func() {
+			m.setSchemas(dbService, databases, results, errs)
+		})
+	}()
+}
+
+// setSchemas 把加载结果显示在数据库树中，加载期间切换了连接时丢弃结果
+func (m *MainWindow) setSchemas(dbService service.DatabaseService, databases []string, results [][]model.SchemaObject, errs []error) {
+	if m.dbService != dbService {
+		return
+	}
+	for i, dbName := range databases {
+		if errs[i] != nil {
+			dialog.ShowError(errs[i], m.window)
 			continue
 		}
-		m.dbTables[dbName] = tables
-		m.dbConfig.CurrentDB = dbName
+		m.setSchemaObjects(dbName, results[i])
+	}
+	// 加载期间打开的项目已经指定了当前数据库时保留，否则优先使用连接配置指定的默认数据库
+	if _, ok := m.dbObjects[m.dbConfig.CurrentDB]; !ok {
+		if _, ok := m.dbObjects[m.dbConfig.Database]; ok {
+			m.dbConfig.CurrentDB = m.dbConfig.Database
+		} else if len(databases) > 0 {
+			m.dbConfig.CurrentDB = databases[len(databases)-1]
+		}
 	}
 
 	// 刷新界面
 	m.leftBar.Refresh()
 }
 
+// refreshSchemas 丢弃缓存的表结构并重新加载，用于数据库结构变更后
+func (m *MainWindow) refreshSchemas() {
+	if m.schemaCache == nil {
+		return
+	}
+	m.schemaCache.InvalidateAll()
+	m.resetSchemaObjects()
+	m.loadSchemas()
+}
+
 func (m *MainWindow) generateSQL() {
-	// 创建SQL生成器
+	sql, err := m.buildSQL()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	// 显示生成的SQL
+	m.setPreview(sql)
+}
+
+// buildSQL 按画布和右侧面板的设置生成SQL
+func (m *MainWindow) buildSQL() (string, error) {
+	generator, err := m.newGenerator()
+	if err != nil {
+		return "", err
+	}
+	return generator.GenerateSQL()
+}
+
+// newGenerator 按画布和右侧面板的设置创建SQL生成器
+func (m *MainWindow) newGenerator() (*service.SQLGenerator, error) {
+	// 创建SQL生成器，使用预览区选择的方言
 	generator := service.NewSQLGenerator()
+	generator.SetDialect(service.DialectByName(m.dialectSelect.Selected))
 
 	// 设置主表
 	mainTable := m.canvas.GetMainTable()
 	if mainTable == "" {
-		dialog.ShowError(fmt.Errorf("Please add a table first"), m.window)
-		return
+		return nil, fmt.Errorf("Please add a table first")
+	}
+	for _, instance := range m.canvas.GetTableInstances() {
+		generator.AddTableInstance(instance)
 	}
 	generator.SetMainTable(mainTable)
 
-	// 添加选中的列
-	selectedColumns := m.canvas.GetAllSelectedColumns()
+	// 按输出顺序添加选中的列
+	selectedColumns := m.canvas.GetOutputColumns()
 	if len(selectedColumns) == 0 {
-		dialog.ShowError(fmt.Errorf("Please select the columns to query"), m.window)
-		return
+		return nil, fmt.Errorf("Please select the columns to query")
 	}
-	for table, columns := range selectedColumns {
-		generator.AddSelectedColumns(table, columns)
+	for _, column := range selectedColumns {
+		generator.AddSelectedColumn(column)
 	}
 
 	// 添加连接信息
-	joins := m.canvas.GetAllJoins()
-	for _, join := range joins {
-		generator.AddJoin(
-			join.SourceTable,
-			join.TargetTable,
-			join.SourceColumn,
-			join.TargetColumn,
-		)
+	for _, join := range m.canvas.GetAllJoins() {
+		generator.AddJoin(join)
 	}
 
-	// 生成SQL
-	sql, err := generator.GenerateSQL()
+	// 添加过滤条件
+	generator.SetWhere(m.filterPanel.Filter())
+	generator.SetHaving(m.havingPanel.Filter())
+
+	// 设置去重和分页
+	limit, err := parseCount(m.limitEntry.Text)
 	if err != nil {
-		dialog.ShowError(err, m.window)
+		return nil, fmt.Errorf("Invalid limit: %v", err)
+	}
+	offset, err := parseCount(m.offsetEntry.Text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid offset: %v", err)
+	}
+	generator.SetDistinct(m.distinctCheck.Checked)
+	generator.SetLimit(limit, offset)
+
+	return generator, nil
+}
+
+// refreshOutputColumns 根据画布刷新输出列列表
+func (m *MainWindow) refreshOutputColumns() {
+	if m.outputList == nil || m.canvas == nil {
 		return
 	}
+	m.outputList.SetItems(m.canvas.GetOutputColumnLabels())
+}
 
-	// 显示生成的SQL
-	m.rightBar.SetText(sql)
+// parseCount 解析非负整数输入，空字符串视为 0
+func parseCount(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return n, nil
 }
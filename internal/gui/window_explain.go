@@ -0,0 +1,81 @@
+package gui
+
+import (
+	"context"
+
+	"github.com/lowSqlGen/internal/service"
+)
+
+// explainQuery 在后台获取预览语句的执行计划，显示在结果区并在画布上标出有问题的表
+func (m *MainWindow) explainQuery() {
+	sql, dbName, ok := m.runnableSQL()
+	if !ok {
+		return
+	}
+	refs := m.planTableRefs()
+
+	m.explain.SetRunning()
+	m.resultTabs.SelectIndex(1)
+
+	dbService, driver := m.dbService, m.dbConfig.Driver
+	go func() {
+		// EXPLAIN 不执行语句，很快就会返回，因此不提供取消
+		plan, err := service.Explain(context.Background(), dbService, driver, dbName, sql)
+		m.runOnUI(func() {
+			m.showPlan(plan, err, refs)
+		})
+	}()
+}
+
+// showPlan 显示执行计划，并按 refs 把各步骤对应到画布上的表实例
+func (m *MainWindow) showPlan(plan *service.PlanNode, err error, refs map[string]string) {
+	m.explain.SetPlan(plan, err)
+	if err != nil {
+		m.canvas.ShowPlanWarnings(nil)
+		return
+	}
+
+	// 同一个表实例被多个步骤访问时保留最严重的问题
+	steps := make(map[string]*service.PlanNode)
+	plan.Walk(func(step *service.PlanNode) {
+		id, ok := refs[step.Table]
+		if !ok {
+			return
+		}
+		if prev, exists := steps[id]; !exists || planSeverity(step) > planSeverity(prev) {
+			steps[id] = step
+		}
+	})
+	m.canvas.ShowPlanWarnings(steps)
+}
+
+// planTableRefs 返回语句中的表名或别名到画布表实例的映射
+func (m *MainWindow) planTableRefs() map[string]string {
+	refs := make(map[string]string)
+	if generator, err := m.newGenerator(); err == nil {
+		if _, err := generator.Build(); err == nil {
+			for id, alias := range generator.TableAliases() {
+				refs[alias] = id
+			}
+		}
+	}
+	// 没有别名的实例在语句中使用表名
+	for _, instance := range m.canvas.GetTableInstances() {
+		if _, exists := refs[instance.Table]; !exists {
+			refs[instance.Table] = instance.ID
+		}
+	}
+	return refs
+}
+
+// planSeverity 步骤问题的严重程度，用于比较
+func planSeverity(step *service.PlanNode) int {
+	switch {
+	case step.NoIndex:
+		return 2
+	case step.FullScan:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,80 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// exportProgressInterval 导出进度两次更新的最短间隔
+const exportProgressInterval = 200 * time.Millisecond
+
+// showExportDialog 选择导出格式和文件，导出预览中语句的全部结果
+func (m *MainWindow) showExportDialog() {
+	sql, dbName, ok := m.runnableSQL()
+	if !ok {
+		return
+	}
+	table := ""
+	if node, ok := m.canvas.tables[m.canvas.GetMainTable()]; ok {
+		table = node.tableName
+	}
+
+	exportDialog := NewExportDialog(m.window, table, service.DialectByName(m.dialectSelect.Selected))
+	exportDialog.SetOnConfirm(func(opts service.ExportOptions) {
+		save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			path := writer.URI().Path()
+			writer.Close()
+			m.exportResults(sql, dbName, path, opts)
+		}, m.window)
+		save.SetFilter(storage.NewExtensionFileFilter([]string{opts.Format.Extension()}))
+		save.SetFileName("result" + opts.Format.Extension())
+		save.Show()
+	})
+	exportDialog.Show()
+}
+
+// exportResults 在后台逐行导出，进度显示在结果标签页中
+func (m *MainWindow) exportResults(sql, dbName, path string, opts service.ExportOptions) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if !m.results.Start(cancel) {
+		cancel()
+		os.Remove(path)
+		dialog.ShowInformation("Export", "A query is already running", m.window)
+		return
+	}
+	m.results.Progress("Exporting...")
+	m.resultTabs.SelectIndex(0)
+
+	dbService := m.dbService
+	go func() {
+		defer cancel()
+		// 大量数据导出时限制进度更新的频率，避免占满事件队列
+		var reported time.Time
+		count, err := service.ExportFile(ctx, dbService, dbName, sql, path, opts, func(rows int) {
+			if time.Since(reported) < exportProgressInterval {
+				return
+			}
+			reported = time.Now()
+			m.runOnUI(func() {
+				m.results.Progress(fmt.Sprintf("Exporting... %d rows", rows))
+			})
+		})
+		// 保存对话框已经创建了目标文件，导出失败时删除，避免留下空文件
+		if err != nil {
+			os.Remove(path)
+		}
+		m.runOnUI(func() {
+			m.results.FinishTask(fmt.Sprintf("Exported %d rows to %s", count, path), err)
+		})
+	}()
+}
@@ -0,0 +1,97 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// showImportDialog 弹出输入框，把粘贴的 SELECT 语句还原到画布上
+func (m *MainWindow) showImportDialog() {
+	if m.dbService == nil {
+		dialog.ShowError(fmt.Errorf("Please connect to a database first"), m.window)
+		return
+	}
+
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetPlaceHolder("SELECT ... FROM ... JOIN ... WHERE ...")
+	entry.SetText(m.rightBar.Text)
+
+	d := dialog.NewCustomConfirm("Import SQL", "Import", "Cancel", entry, func(ok bool) {
+		if ok {
+			m.importSQL(entry.Text)
+		}
+	}, m.window)
+	d.Resize(fyne.NewSize(600, 400))
+	d.Show()
+}
+
+// importSQL 解析语句并还原到画布，无法表示的部分以提示列出
+func (m *MainWindow) importSQL(sql string) {
+	dbName, err := m.queryDatabase()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	columnsOf := m.columnLookup(dbName)
+	query, err := service.ImportSQL(sql, service.DialectByName(m.dialectSelect.Selected), columnsOf)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	if err := m.applyQuery(query, dbName, columnsOf); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.generateSQL()
+	if len(query.Unsupported) > 0 {
+		dialog.ShowInformation("Import warnings",
+			"The following parts cannot be represented on the canvas and were ignored:\n\n"+
+				strings.Join(query.Unsupported, "\n"), m.window)
+	}
+}
+
+// applyQuery 按导入结果重建画布、条件面板和分页设置，期间不重新生成预览。
+// 导入的表都属于 dbName
+func (m *MainWindow) applyQuery(query *service.ImportedQuery, dbName string, columnsOf service.ColumnLookup) error {
+	m.sync.applying = true
+	defer func() { m.sync.applying = false }()
+
+	if err := m.canvas.ImportQuery(query, dbName, columnsOf); err != nil {
+		return err
+	}
+	m.filterPanel.SetFilter(query.Where)
+	m.havingPanel.SetFilter(query.Having)
+	m.distinctCheck.SetChecked(query.Distinct)
+	m.limitEntry.SetText(formatCount(query.Limit))
+	m.offsetEntry.SetText(formatCount(query.Offset))
+
+	m.currentAddedTable = query.Tables[0].Table
+	m.firstTable = false
+	m.leftBar.Refresh()
+	return nil
+}
+
+// columnLookup 从指定数据库读取表的列，连接在创建时确定，可以在后台协程中使用
+func (m *MainWindow) columnLookup(dbName string) service.ColumnLookup {
+	dbService := m.dbService
+	return func(table string) ([]model.Column, error) {
+		return dbService.GetColumns(dbName, table)
+	}
+}
+
+// formatCount 与 parseCount 相反，0 显示为空
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,245 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
+	"github.com/lowSqlGen/internal/service"
+)
+
+const (
+	recentProjectsKey = "recentProjects" // 最近打开的项目文件在偏好设置中的键
+	maxRecentProjects = 8
+)
+
+// buildMainMenu 创建文件菜单，最近打开的文件变化后需要重新创建
+func (m *MainWindow) buildMainMenu() *fyne.MainMenu {
+	recent := fyne.NewMenuItem("Recent Files", nil)
+	recent.ChildMenu = fyne.NewMenu("")
+	for _, path := range m.recentProjects() {
+		path := path
+		recent.ChildMenu.Items = append(recent.ChildMenu.Items, fyne.NewMenuItem(path, func() {
+			m.openProjectFile(path)
+		}))
+	}
+	if len(recent.ChildMenu.Items) == 0 {
+		empty := fyne.NewMenuItem("(empty)", nil)
+		empty.Disabled = true
+		recent.ChildMenu.Items = append(recent.ChildMenu.Items, empty)
+	}
+
+	return fyne.NewMainMenu(fyne.NewMenu("File",
+		fyne.NewMenuItem("Open...", m.showOpenProject),
+		recent,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Save", m.saveProject),
+		fyne.NewMenuItem("Save As...", m.showSaveProjectAs),
+	))
+}
+
+// showOpenProject 选择并打开项目文件
+func (m *MainWindow) showOpenProject() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		path := reader.URI().Path()
+		reader.Close()
+		m.openProjectFile(path)
+	}, m.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
+
+// saveProject 保存到当前项目文件，尚未保存过时询问文件名
+func (m *MainWindow) saveProject() {
+	if m.projectPath == "" {
+		m.showSaveProjectAs()
+		return
+	}
+	m.writeProject(m.projectPath)
+}
+
+func (m *MainWindow) showSaveProjectAs() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		path := writer.URI().Path()
+		writer.Close()
+		m.writeProject(path)
+	}, m.window)
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	save.SetFileName("query.json")
+	save.Show()
+}
+
+// writeProject 把当前的连接、画布和生成选项写入项目文件
+func (m *MainWindow) writeProject(path string) {
+	if m.dbConfig == nil || m.dbService == nil {
+		dialog.ShowError(fmt.Errorf("Please connect to a database first"), m.window)
+		return
+	}
+	limit, err := parseCount(m.limitEntry.Text)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Invalid limit: %v", err), m.window)
+		return
+	}
+	offset, err := parseCount(m.offsetEntry.Text)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("Invalid offset: %v", err), m.window)
+		return
+	}
+
+	project := &service.Project{
+		Connection: service.NewConnectionRef(m.dbConfig),
+		Database:   m.dbConfig.CurrentDB,
+		Options: service.GeneratorOptions{
+			Dialect:  m.dialectSelect.Selected,
+			Distinct: m.distinctCheck.Checked,
+			Limit:    limit,
+			Offset:   offset,
+			Where:    m.filterPanel.Filter(),
+			Having:   m.havingPanel.Filter(),
+		},
+	}
+	m.canvas.FillProject(project)
+	if err := service.SaveProject(path, project); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	m.setProjectPath(path)
+}
+
+// openProjectFile 读取项目文件，需要时先连接项目使用的数据库
+func (m *MainWindow) openProjectFile(path string) {
+	project, err := service.LoadProject(path)
+	if err != nil {
+		m.removeRecentProject(path)
+		dialog.ShowError(err, m.window)
+		return
+	}
+	if m.dbService != nil && project.Connection.Matches(m.dbConfig) {
+		m.applyProject(project, path)
+		return
+	}
+
+	// 优先使用项目记录的连接配置
+	if name := project.Connection.Profile; name != "" && m.profiles != nil && m.profiles.HasProfile(name) {
+		if m.profiles.NeedsUnlock(name) {
+			ShowPassphraseDialog(m.window, m.profiles, func() {
+				m.openProjectFile(path)
+			}, nil)
+			return
+		}
+		config, err := m.profiles.Config(name)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		m.dbConfig = config
+		if m.connectToDatabase() {
+			m.applyProject(project, path)
+		}
+		return
+	}
+
+	config := project.Connection.Config()
+	if config.Driver == model.DriverSQLite {
+		m.dbConfig = config
+		if m.connectToDatabase() {
+			m.applyProject(project, path)
+		}
+		return
+	}
+
+	// 项目文件不保存密码，连接前询问
+	password := widget.NewPasswordEntry()
+	target := fmt.Sprintf("%s@%s:%s", config.Username, config.Host, config.Port)
+	dialog.ShowForm("Connect to "+target, "Connect", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Password", password)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			config.Password = password.Text
+			m.dbConfig = config
+			if m.connectToDatabase() {
+				m.applyProject(project, path)
+			}
+		}, m.window)
+}
+
+// applyProject 按项目重建画布和生成选项
+func (m *MainWindow) applyProject(project *service.Project, path string) {
+	m.dbConfig.CurrentDB = project.Database
+
+	m.sync.applying = true
+	warnings, err := m.canvas.LoadProject(project)
+	if err == nil {
+		options := project.Options
+		if options.Dialect != "" {
+			m.dialectSelect.SetSelected(options.Dialect)
+		}
+		m.distinctCheck.SetChecked(options.Distinct)
+		m.limitEntry.SetText(formatCount(options.Limit))
+		m.offsetEntry.SetText(formatCount(options.Offset))
+		m.filterPanel.SetFilter(options.Where)
+		m.havingPanel.SetFilter(options.Having)
+	}
+	m.sync.applying = false
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.firstTable = len(project.Tables) == 0
+	m.currentAddedTable = ""
+	if len(project.Tables) > 0 {
+		m.currentAddedTable = project.Tables[0].Table
+	}
+	m.leftBar.Refresh()
+	m.refreshPreview()
+	m.setProjectPath(path)
+
+	if len(warnings) > 0 {
+		dialog.ShowInformation("Project warnings", strings.Join(warnings, "\n"), m.window)
+	}
+}
+
+// setProjectPath 记录当前项目文件，显示在标题中并加入最近打开的文件
+func (m *MainWindow) setProjectPath(path string) {
+	m.projectPath = path
+	m.window.SetTitle(filepath.Base(path) + " - " + m.baseTitle)
+
+	recent := []string{path}
+	for _, existing := range m.recentProjects() {
+		if existing != path && len(recent) < maxRecentProjects {
+			recent = append(recent, existing)
+		}
+	}
+	fyne.CurrentApp().Preferences().SetStringList(recentProjectsKey, recent)
+	m.window.SetMainMenu(m.buildMainMenu())
+}
+
+// removeRecentProject 从最近打开的文件中移除无法打开的文件
+func (m *MainWindow) removeRecentProject(path string) {
+	var recent []string
+	for _, existing := range m.recentProjects() {
+		if existing != path {
+			recent = append(recent, existing)
+		}
+	}
+	fyne.CurrentApp().Preferences().SetStringList(recentProjectsKey, recent)
+	m.window.SetMainMenu(m.buildMainMenu())
+}
+
+func (m *MainWindow) recentProjects() []string {
+	return fyne.CurrentApp().Preferences().StringList(recentProjectsKey)
+}
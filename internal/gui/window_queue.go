@@ -0,0 +1,16 @@
+package gui
+
+// eventQueue 桌面窗口在单独的事件协程中依次处理所有输入事件，
+// 画布和面板的状态只在这个协程中修改
+type eventQueue interface {
+	QueueEvent(fn func())
+}
+
+// runOnUI 把后台协程的结果交给事件协程应用，窗口不支持事件队列时直接调用
+func (m *MainWindow) runOnUI(fn func()) {
+	if queue, ok := m.window.(eventQueue); ok {
+		queue.QueueEvent(fn)
+		return
+	}
+	fn()
+}
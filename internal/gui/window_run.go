@@ -0,0 +1,87 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// rowLimits 执行查询时可选的行数上限
+var rowLimits = []string{"100", "1000", "10000", "100000"}
+
+// runQuery 在后台执行预览中的语句，结果显示在画布下方
+func (m *MainWindow) runQuery() {
+	sql, dbName, ok := m.runnableSQL()
+	if !ok {
+		return
+	}
+	maxRows, _ := strconv.Atoi(m.rowLimitSelect.Selected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if !m.results.Start(cancel) {
+		cancel()
+		dialog.ShowInformation("Run", "A query is already running", m.window)
+		return
+	}
+	m.resultTabs.SelectIndex(0)
+
+	dbService := m.dbService
+	go func() {
+		defer cancel()
+		result, err := service.Execute(ctx, dbService, dbName, sql, maxRows)
+		m.runOnUI(func() {
+			m.results.Finish(result, err)
+		})
+	}()
+}
+
+// runnableSQL 返回要执行的语句及执行它的数据库，预览为空时先按画布生成。只允许执行 SELECT 语句
+func (m *MainWindow) runnableSQL() (string, string, bool) {
+	if m.dbService == nil {
+		dialog.ShowError(fmt.Errorf("Please connect to a database first"), m.window)
+		return "", "", false
+	}
+	dbName, err := m.queryDatabase()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return "", "", false
+	}
+	sql := strings.TrimSpace(m.rightBar.Text)
+	if sql == "" {
+		generated, err := m.buildSQL()
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return "", "", false
+		}
+		m.setPreview(generated)
+		sql = generated
+	}
+	if err := service.CheckQuery(sql, service.DialectFor(m.dbConfig.Driver)); err != nil {
+		dialog.ShowError(err, m.window)
+		return "", "", false
+	}
+	return sql, dbName, true
+}
+
+// queryDatabase 返回画布上的表所在的数据库，画布为空时为当前数据库。
+// 语句不带数据库名，因此不能混用不同数据库中的表
+func (m *MainWindow) queryDatabase() (string, error) {
+	var databases []string
+	for _, instance := range m.canvas.GetTableInstances() {
+		if !containsString(databases, instance.Database) {
+			databases = append(databases, instance.Database)
+		}
+	}
+	switch len(databases) {
+	case 0:
+		return m.dbConfig.CurrentDB, nil
+	case 1:
+		return databases[0], nil
+	default:
+		return "", fmt.Errorf("The canvas uses tables from more than one database: %s", strings.Join(databases, ", "))
+	}
+}
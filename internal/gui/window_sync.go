@@ -0,0 +1,127 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/service"
+)
+
+// previewSyncDelay 停止编辑多久之后把预览中的语句应用到画布
+const previewSyncDelay = 600 * time.Millisecond
+
+// previewSync 预览区与画布的双向同步状态
+type previewSync struct {
+	generated string      // 画布当前内容对应的语句
+	applying  bool        // 正在把语句应用到画布，期间忽略画布的变化通知
+	timer     *time.Timer // 编辑停顿后触发解析
+}
+
+// OnConnectionChanged 画布发生变化后重新生成预览
+func (m *MainWindow) OnConnectionChanged(*TableConnection) {
+	m.refreshPreview()
+}
+
+// refreshPreview 按画布重新生成预览，画布设置不完整时保留预览内容并显示原因
+func (m *MainWindow) refreshPreview() {
+	if m.sync.applying || m.canvas == nil || m.dialectSelect == nil {
+		return
+	}
+	if m.canvas.GetMainTable() == "" {
+		m.setPreview("")
+		return
+	}
+	sql, err := m.buildSQL()
+	if err != nil {
+		m.setSyncStatus(err.Error(), widget.WarningImportance)
+		return
+	}
+	m.setPreview(sql)
+}
+
+// setPreview 显示由画布生成的语句
+func (m *MainWindow) setPreview(sql string) {
+	m.sync.generated = sql
+	m.rightBar.SetText(sql)
+	m.setSyncStatus("", widget.MediumImportance)
+}
+
+// onPreviewEdited 预览内容变化时调用，用户编辑的语句在停顿后于后台解析，
+// 解析结果回到事件协程后再应用到画布
+func (m *MainWindow) onPreviewEdited(text string) {
+	if m.sync.timer != nil {
+		m.sync.timer.Stop()
+	}
+	if text == m.sync.generated {
+		m.setSyncStatus("", widget.MediumImportance)
+		return
+	}
+	if m.dbService == nil {
+		m.showConflict(fmt.Errorf("connect to a database first"))
+		return
+	}
+	dbName, err := m.queryDatabase()
+	if err != nil {
+		m.showConflict(err)
+		return
+	}
+	m.setSyncStatus("Editing...", widget.LowImportance)
+
+	dialect := service.DialectByName(m.dialectSelect.Selected)
+	columnsOf := m.columnLookup(dbName)
+	m.sync.timer = time.AfterFunc(previewSyncDelay, func() {
+		query, sql, err := parsePreview(text, dialect, columnsOf)
+		m.runOnUI(func() {
+			m.applyPreview(text, dbName, query, sql, err, columnsOf)
+		})
+	})
+}
+
+// parsePreview 解析编辑后的语句并按画布的方式重新生成，语句中有画布无法表示的部分时返回错误
+func parsePreview(text string, dialect service.Dialect, columnsOf service.ColumnLookup) (*service.ImportedQuery, string, error) {
+	query, err := service.ImportSQL(text, dialect, columnsOf)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(query.Unsupported) > 0 {
+		return nil, "", fmt.Errorf("%s", strings.Join(query.Unsupported, "; "))
+	}
+	sql, err := query.SQL(dialect)
+	if err != nil {
+		return nil, "", err
+	}
+	return query, sql, nil
+}
+
+// applyPreview 把解析结果应用到画布。解析期间预览又被修改时丢弃结果；
+// 有冲突时不修改画布，只显示冲突；内容与画布相同时（例如只改了格式）不重建画布
+func (m *MainWindow) applyPreview(text, dbName string, query *service.ImportedQuery, sql string, err error, columnsOf service.ColumnLookup) {
+	if m.rightBar.Text != text {
+		return
+	}
+	if err != nil {
+		m.showConflict(err)
+		return
+	}
+
+	if sql != m.sync.generated {
+		if err := m.applyQuery(query, dbName, columnsOf); err != nil {
+			m.showConflict(err)
+			return
+		}
+		m.sync.generated = sql
+	}
+	m.setSyncStatus("Synced with canvas", widget.SuccessImportance)
+}
+
+// showConflict 显示预览与画布不一致的原因
+func (m *MainWindow) showConflict(err error) {
+	m.setSyncStatus("Conflict, canvas not updated: "+err.Error(), widget.DangerImportance)
+}
+
+func (m *MainWindow) setSyncStatus(text string, importance widget.Importance) {
+	m.syncLabel.Importance = importance
+	m.syncLabel.SetText(text)
+}
@@ -0,0 +1,203 @@
+package gui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/lowSqlGen/internal/model"
+)
+
+// schemaFolder 数据库下按对象类型分组的目录
+type schemaFolder struct {
+	key   string // 目录在树节点ID中的名称
+	label string
+	types []string
+}
+
+// schemaFolders 目录的显示顺序，表目录总是显示，其他目录没有对象时隐藏
+var schemaFolders = []schemaFolder{
+	{"tables", "Tables", []string{model.ObjectTable}},
+	{"views", "Views", []string{model.ObjectView}},
+	{"routines", "Routines", []string{model.ObjectProcedure, model.ObjectFunction}},
+	{"triggers", "Triggers", []string{model.ObjectTrigger}},
+}
+
+// schemaNode 树中的目录或对象节点，目录节点的 object 为 nil
+type schemaNode struct {
+	dbName string
+	folder *schemaFolder
+	object *model.SchemaObject
+}
+
+// newSchemaTree 创建左侧的数据库树：数据库 -> 目录 -> 对象。
+// 表和视图可以添加到画布，存储过程、函数和触发器可以查看定义
+func (m *MainWindow) newSchemaTree() *widget.Tree {
+	return widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			if id == "" {
+				// root节点返回所有数据库
+				return m.getDatabases()
+			}
+			return m.schemaChildren[id]
+		},
+		func(id widget.TreeNodeID) bool {
+			node, ok := m.schemaNodes[id]
+			return !ok || node.object == nil
+		},
+		func(branch bool) fyne.CanvasObject {
+			if branch {
+				return widget.NewLabel("Template")
+			}
+			// 为对象节点创建一个容器，包含标签和按钮
+			label := widget.NewLabel("Template")
+			btn := widget.NewButton("Add", nil)
+			return container.NewBorder(nil, nil, nil, btn, label)
+		},
+		func(id widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+			node, ok := m.schemaNodes[id]
+			if branch {
+				label := obj.(*widget.Label)
+				if ok {
+					label.SetText(fmt.Sprintf("%s (%d)", node.folder.label, len(m.schemaChildren[id])))
+				} else {
+					label.SetText(id)
+				}
+				return
+			}
+			if !ok {
+				return
+			}
+
+			cont := obj.(*fyne.Container)
+			label := cont.Objects[0].(*widget.Label)
+			btn := cont.Objects[1].(*widget.Button)
+			switch node.object.Type {
+			case model.ObjectTable, model.ObjectView:
+				m.updateTableItem(node.dbName, node.object.Name, label, btn)
+			default:
+				m.updateRoutineItem(node.dbName, *node.object, label, btn)
+			}
+		},
+	)
+}
+
+// updateTableItem 显示表或视图及其注释，按钮用于把它作为第一张表添加到画布
+func (m *MainWindow) updateTableItem(dbName, tableName string, label *widget.Label, btn *widget.Button) {
+	// 设置表名和注释
+	if m.dbService != nil {
+		comment := m.dbService.GetTableComment(dbName, tableName)
+		if comment != "" {
+			label.SetText(fmt.Sprintf("%s // %s", tableName, comment))
+		} else {
+			label.SetText(tableName)
+		}
+	} else {
+		label.SetText(tableName)
+	}
+
+	// 根据状态设置按钮
+	if m.currentAddedTable == tableName {
+		btn.SetText("Cancel")
+		btn.Show()
+		btn.OnTapped = func() {
+			m.canvas.Clear()
+			m.currentAddedTable = ""
+			m.firstTable = true
+			m.leftBar.Refresh()
+		}
+		return
+	}
+	btn.SetText("Add")
+	if m.firstTable || m.currentAddedTable == "" {
+		btn.Show()
+	} else {
+		btn.Hide()
+	}
+	btn.OnTapped = func() {
+		columns, err := m.dbService.GetColumns(dbName, tableName)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		m.canvas.AddTable(dbName, tableName, columns)
+		m.window.Canvas().Refresh(m.canvas.container)
+
+		m.dbConfig.CurrentDB = dbName
+		m.currentAddedTable = tableName
+		m.firstTable = false
+		m.leftBar.Refresh()
+	}
+}
+
+// updateRoutineItem 显示存储过程、函数或触发器，按钮用于查看定义
+func (m *MainWindow) updateRoutineItem(dbName string, object model.SchemaObject, label *widget.Label, btn *widget.Button) {
+	if object.Type == model.ObjectTrigger {
+		label.SetText(fmt.Sprintf("%s (on %s)", object.Name, object.Table))
+	} else {
+		label.SetText(fmt.Sprintf("%s (%s)", object.Name, object.Type))
+	}
+	title := label.Text
+	btn.SetText("View")
+	btn.Show()
+	btn.OnTapped = func() {
+		definition, err := m.dbService.GetDefinition(dbName, object)
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		ShowDefinitionDialog(m.window, title, definition)
+	}
+}
+
+// setSchemaObjects 记录数据库中的对象并生成树节点。
+// 节点ID为 数据库/目录 和 数据库/目录/类型:对象，触发器为 数据库/目录/类型:表.触发器，
+// 同名的存储过程和函数因类型不同而不会冲突
+func (m *MainWindow) setSchemaObjects(dbName string, objects []model.SchemaObject) {
+	m.dbObjects[dbName] = objects
+
+	var folderIDs []widget.TreeNodeID
+	for i := range schemaFolders {
+		folder := &schemaFolders[i]
+		folderID := dbName + "/" + folder.key
+		var children []widget.TreeNodeID
+		for j := range objects {
+			object := &objects[j]
+			if !containsString(folder.types, object.Type) {
+				continue
+			}
+			name := object.Name
+			if object.Type == model.ObjectTrigger {
+				name = object.Table + "." + object.Name
+			}
+			id := folderID + "/" + object.Type + ":" + name
+			m.schemaNodes[id] = schemaNode{dbName: dbName, folder: folder, object: object}
+			children = append(children, id)
+		}
+		if len(children) == 0 && folder.key != "tables" {
+			continue
+		}
+		m.schemaNodes[folderID] = schemaNode{dbName: dbName, folder: folder}
+		m.schemaChildren[folderID] = children
+		folderIDs = append(folderIDs, folderID)
+	}
+	m.schemaChildren[dbName] = folderIDs
+}
+
+// resetSchemaObjects 清空数据库树
+func (m *MainWindow) resetSchemaObjects() {
+	m.dbObjects = make(map[string][]model.SchemaObject)
+	m.schemaNodes = make(map[widget.TreeNodeID]schemaNode)
+	m.schemaChildren = make(map[widget.TreeNodeID][]widget.TreeNodeID)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,109 @@
 package model
 
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// TLS 连接的加密方式
+const (
+	TLSDisabled = ""        // 不加密
+	TLSRequire  = "require" // 加密，但不校验服务器证书
+	TLSVerify   = "verify"  // 加密并校验服务器证书和主机名
+)
+
 // DatabaseConfig 数据库连接配置
 type DatabaseConfig struct {
-	Host      string
-	Port      string
-	Username  string
-	Password  string
-	Database  string
-	CurrentDB string
+	Profile   string     `json:"-"`                // 使用的连接配置名称，手动填写时为空
+	Driver    string     `json:"driver,omitempty"` // 为空时使用MySQL
+	Host      string     `json:"host,omitempty"`
+	Port      string     `json:"port,omitempty"`
+	Username  string     `json:"username,omitempty"`
+	Password  string     `json:"-"`
+	Database  string     `json:"database,omitempty"` // 默认数据库
+	FilePath  string     `json:"filePath,omitempty"` // SQLite 数据库文件
+	Charset   string     `json:"charset,omitempty"`  // 连接字符集，为空时使用驱动的默认值
+	Timeout   int        `json:"timeout,omitempty"`  // 连接超时（秒），0 表示使用驱动的默认值
+	TLS       TLSOptions `json:"tls"`
+	CurrentDB string     `json:"-"`
+}
+
+// TLSOptions 加密连接的设置
+type TLSOptions struct {
+	Mode     string `json:"mode,omitempty"`     // TLSDisabled、TLSRequire 或 TLSVerify
+	CAFile   string `json:"caFile,omitempty"`   // 校验服务器证书使用的CA，为空时使用系统证书
+	CertFile string `json:"certFile,omitempty"` // 客户端证书，服务器要求双向认证时使用
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// Schema 一个数据库中所有表的结构
+type Schema struct {
+	Name   string
+	Tables []Table
 }
 
 // Table 表结构
 type Table struct {
 	Name    string
+	Comment string
 	Columns []Column
 }
 
 // Column 列结构
 type Column struct {
-	Name     string
-	Type     string
-	Selected bool
+	Name       string
+	Type       string // 完整类型，例如 varchar(64)、int unsigned
+	Nullable   bool
+	Key        string // PRI、UNI、MUL
+	Default    string
+	HasDefault bool   // 区分没有默认值和默认值为空字符串
+	Extra      string // 例如 auto_increment
+	Comment    string
+	Charset    string
+	Selected   bool
+}
+
+// ColumnNames 返回列名列表
+func ColumnNames(columns []Column) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// ForeignKey 外键约束，Columns 与 RefColumns 按位置一一对应
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// Index 表上的索引，Columns 按索引中的顺序排列，表达式索引的表达式部分为空字符串
+type Index struct {
+	Name    string
+	Table   string
+	Columns []string
+	Primary bool
+	Unique  bool
+}
+
+// 数据库对象的类型
+const (
+	ObjectTable     = "table"
+	ObjectView      = "view"
+	ObjectProcedure = "procedure"
+	ObjectFunction  = "function"
+	ObjectTrigger   = "trigger"
+)
+
+// SchemaObject 数据库中的表、视图、存储过程、函数或触发器
+type SchemaObject struct {
+	Name  string
+	Type  string // ObjectTable 等
+	Table string // 触发器所在的表，其他对象为空
 }
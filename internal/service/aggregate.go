@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+)
+
+// AggregateFunc 聚合函数
+type AggregateFunc string
+
+const (
+	AggNone          AggregateFunc = ""
+	AggCount         AggregateFunc = "COUNT"
+	AggSum           AggregateFunc = "SUM"
+	AggAvg           AggregateFunc = "AVG"
+	AggMin           AggregateFunc = "MIN"
+	AggMax           AggregateFunc = "MAX"
+	AggCountDistinct AggregateFunc = "COUNT DISTINCT"
+	AggGroupConcat   AggregateFunc = "GROUP_CONCAT"
+)
+
+// AggregateFuncs 返回所有可选的聚合函数（不含 AggNone）
+func AggregateFuncs() []AggregateFunc {
+	return []AggregateFunc{AggCount, AggSum, AggAvg, AggMin, AggMax, AggCountDistinct, AggGroupConcat}
+}
+
+// Apply 把聚合函数作用于列表达式
+func (a AggregateFunc) Apply(expr string) string {
+	switch a {
+	case AggNone:
+		return expr
+	case AggCountDistinct:
+		return fmt.Sprintf("COUNT(DISTINCT %s)", expr)
+	default:
+		return fmt.Sprintf("%s(%s)", a, expr)
+	}
+}
+
+// applyAggregate 按方言应用聚合函数，字符串拼接聚合在各数据库中写法不同
+func applyAggregate(d Dialect, a AggregateFunc, expr string) string {
+	if a == AggGroupConcat {
+		return d.GroupConcat(expr)
+	}
+	return a.Apply(expr)
+}
+
+// ResultType 返回聚合后结果的数据类型，用于确定可用的比较运算符
+func (a AggregateFunc) ResultType(dataType string) string {
+	switch a {
+	case AggCount, AggCountDistinct, AggSum, AggAvg:
+		return "decimal"
+	case AggGroupConcat:
+		return "text"
+	default:
+		return dataType
+	}
+}
+
+// SelectColumn 查询中选中的列及其聚合方式
+type SelectColumn struct {
+	Table        string
+	Name         string
+	Alias        string // 输出别名，为空时使用列名
+	Expression   string // 计算列的表达式，用 {列名} 引用所属表的列
+	Aggregate    AggregateFunc
+	Sort         SortDirection // 为空表示不参与排序
+	SortPriority int           // 数值越小越先排序
+}
+
+// OutputName 返回列在结果集中的名称
+func (c SelectColumn) OutputName() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+	return c.Name
+}
+
+// needsAlias 计算列和设置了别名的列需要输出 AS 子句
+func (c SelectColumn) needsAlias() bool {
+	return c.Alias != "" || c.Expression != ""
+}
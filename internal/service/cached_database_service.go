@@ -0,0 +1,203 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// maxPrefetch 同时在后台加载的数据库数量
+const maxPrefetch = 4
+
+// CachedDatabaseService 缓存表结构的装饰器，每个数据库的表、列和注释只查询一次，
+// 可在后台并发预加载，并支持按数据库刷新
+type CachedDatabaseService struct {
+	DatabaseService // 被装饰的服务，未缓存的方法直接转发
+
+	mu       sync.Mutex
+	schemas  map[string]*schemaEntry
+	indexes  map[string]map[string][]model.Index // 数据库 -> 表 -> 索引，按表单独查询
+	limiter  chan struct{}
+	onLoaded func(dbName string)
+}
+
+// schemaEntry 一个数据库的缓存，ready 关闭后 schema 和 err 可读
+type schemaEntry struct {
+	ready  chan struct{}
+	schema *model.Schema
+	tables map[string]*model.Table
+	err    error
+}
+
+func NewCachedDatabaseService(inner DatabaseService) *CachedDatabaseService {
+	return &CachedDatabaseService{
+		DatabaseService: inner,
+		schemas:         make(map[string]*schemaEntry),
+		indexes:         make(map[string]map[string][]model.Index),
+		limiter:         make(chan struct{}, maxPrefetch),
+	}
+}
+
+// SetOnLoaded 设置数据库加载完成后的回调，回调在后台协程中执行
+func (s *CachedDatabaseService) SetOnLoaded(callback func(dbName string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onLoaded = callback
+}
+
+// Prefetch 在后台并发加载多个数据库，已缓存的数据库不会重复加载
+func (s *CachedDatabaseService) Prefetch(dbNames ...string) {
+	for _, dbName := range dbNames {
+		s.entry(dbName)
+	}
+}
+
+// Invalidate 丢弃数据库的缓存，下次访问时重新加载
+func (s *CachedDatabaseService) Invalidate(dbName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.schemas, dbName)
+	delete(s.indexes, dbName)
+}
+
+// InvalidateAll 丢弃所有缓存
+func (s *CachedDatabaseService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas = make(map[string]*schemaEntry)
+	s.indexes = make(map[string]map[string][]model.Index)
+}
+
+// Refresh 丢弃缓存并立即在后台重新加载
+func (s *CachedDatabaseService) Refresh(dbName string) {
+	s.Invalidate(dbName)
+	s.Prefetch(dbName)
+}
+
+// entry 返回数据库的缓存项，不存在时创建并开始后台加载
+func (s *CachedDatabaseService) entry(dbName string) *schemaEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.schemas[dbName]; ok {
+		return e
+	}
+	e := &schemaEntry{ready: make(chan struct{})}
+	s.schemas[dbName] = e
+	go s.load(dbName, e)
+	return e
+}
+
+func (s *CachedDatabaseService) load(dbName string, e *schemaEntry) {
+	s.limiter <- struct{}{}
+	e.schema, e.err = s.DatabaseService.LoadSchema(dbName)
+	<-s.limiter
+
+	if e.err == nil {
+		e.tables = make(map[string]*model.Table, len(e.schema.Tables))
+		for i := range e.schema.Tables {
+			e.tables[e.schema.Tables[i].Name] = &e.schema.Tables[i]
+		}
+	}
+	close(e.ready)
+
+	s.mu.Lock()
+	callback := s.onLoaded
+	s.mu.Unlock()
+	if callback != nil {
+		callback(dbName)
+	}
+}
+
+// schema 等待数据库加载完成
+func (s *CachedDatabaseService) schema(dbName string) (*schemaEntry, error) {
+	e := s.entry(dbName)
+	<-e.ready
+	if e.err != nil {
+		s.evict(dbName, e)
+		return nil, e.err
+	}
+	return e, nil
+}
+
+// evict 丢弃加载失败的缓存项，以便下次访问时重试
+func (s *CachedDatabaseService) evict(dbName string, e *schemaEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.schemas[dbName] == e {
+		delete(s.schemas, dbName)
+	}
+}
+
+// LoadSchema 返回缓存的表结构
+func (s *CachedDatabaseService) LoadSchema(dbName string) (*model.Schema, error) {
+	e, err := s.schema(dbName)
+	if err != nil {
+		return nil, err
+	}
+	return e.schema, nil
+}
+
+func (s *CachedDatabaseService) GetTables(dbName string) ([]string, error) {
+	e, err := s.schema(dbName)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(e.schema.Tables))
+	for _, table := range e.schema.Tables {
+		tables = append(tables, table.Name)
+	}
+	return tables, nil
+}
+
+// GetColumns 缓存中没有的表（例如加载后新建的表）直接查询
+func (s *CachedDatabaseService) GetColumns(dbName, tableName string) ([]model.Column, error) {
+	e, err := s.schema(dbName)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := e.tables[tableName]
+	if !ok {
+		return s.DatabaseService.GetColumns(dbName, tableName)
+	}
+	return append([]model.Column(nil), table.Columns...), nil
+}
+
+// GetTableComment 不等待加载，数据库尚未加载完成或加载失败时返回空字符串，失败后下次调用重新加载
+func (s *CachedDatabaseService) GetTableComment(dbName, tableName string) string {
+	e := s.entry(dbName)
+	select {
+	case <-e.ready:
+	default:
+		return ""
+	}
+	if e.err != nil {
+		s.evict(dbName, e)
+		return ""
+	}
+	if table, ok := e.tables[tableName]; ok {
+		return table.Comment
+	}
+	return ""
+}
+
+// GetIndexes 每张表的索引只查询一次，不等待也不触发整个数据库的加载
+func (s *CachedDatabaseService) GetIndexes(dbName, tableName string) ([]model.Index, error) {
+	s.mu.Lock()
+	indexes, ok := s.indexes[dbName][tableName]
+	s.mu.Unlock()
+	if ok {
+		return indexes, nil
+	}
+
+	indexes, err := s.DatabaseService.GetIndexes(dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.indexes[dbName] == nil {
+		s.indexes[dbName] = make(map[string][]model.Index)
+	}
+	s.indexes[dbName][tableName] = indexes
+	s.mu.Unlock()
+	return indexes, nil
+}
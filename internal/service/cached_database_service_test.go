@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// flakySchemaService 第一次加载表结构失败，之后成功
+type flakySchemaService struct {
+	DatabaseService
+	mu    sync.Mutex
+	loads int
+}
+
+func (f *flakySchemaService) LoadSchema(dbName string) (*model.Schema, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.loads++
+	if f.loads == 1 {
+		return nil, errors.New("connection reset")
+	}
+	return &model.Schema{Tables: []model.Table{{Name: "emp", Comment: "员工"}}}, nil
+}
+
+// 加载失败后读取注释会重新加载，而不是一直返回空字符串
+func TestTableCommentAfterFailedLoad(t *testing.T) {
+	s := NewCachedDatabaseService(&flakySchemaService{})
+	loaded := make(chan string, 2)
+	s.SetOnLoaded(func(dbName string) { loaded <- dbName })
+
+	wait := func() {
+		t.Helper()
+		select {
+		case <-loaded:
+		case <-time.After(5 * time.Second):
+			t.Fatal("schema was not loaded")
+		}
+	}
+
+	s.Prefetch("hr")
+	wait()
+	if got := s.GetTableComment("hr", "emp"); got != "" {
+		t.Errorf("comment after failed load = %q, want empty", got)
+	}
+	// 失败的缓存项已丢弃，下一次调用开始重新加载
+	s.GetTableComment("hr", "emp")
+	wait()
+	if got := s.GetTableComment("hr", "emp"); got != "员工" {
+		t.Errorf("comment after reload = %q, want 员工", got)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import "github.com/lowSqlGen/internal/model"
+
+// ColumnKeys 列在主键、外键和索引中的作用，用于在表节点上显示标记
+type ColumnKeys struct {
+	Primary bool // 属于主键
+	Foreign bool // 属于引用其他表的外键
+	Indexed bool // 是主键以外某个索引的第一列
+}
+
+// TableColumnKeys 按表的索引和外键计算各列的作用，没有任何作用的列不出现在结果中
+func TableColumnKeys(table string, indexes []model.Index, foreignKeys []model.ForeignKey) map[string]ColumnKeys {
+	keys := make(map[string]ColumnKeys)
+	for _, index := range indexes {
+		for i, column := range index.Columns {
+			k := keys[column]
+			if index.Primary {
+				k.Primary = true
+			} else if i == 0 {
+				k.Indexed = true
+			}
+			keys[column] = k
+		}
+	}
+	for _, fk := range foreignKeys {
+		if fk.Table != table {
+			continue
+		}
+		for _, column := range fk.Columns {
+			k := keys[column]
+			k.Foreign = true
+			keys[column] = k
+		}
+	}
+	delete(keys, "")
+	return keys
+}
+
+// IndexUsable 判断按列过滤或连接时能否使用索引：列在某个索引中，且索引中排在它前面的列都在 bound 中。
+// bound 为同一条件中一并给出的其他列，例如复合连接的其余列
+func IndexUsable(indexes []model.Index, column string, bound []string) bool {
+	given := map[string]bool{column: true}
+	for _, b := range bound {
+		given[b] = true
+	}
+	for _, index := range indexes {
+		for _, c := range index.Columns {
+			if c == column {
+				return true
+			}
+			if !given[c] {
+				break
+			}
+		}
+	}
+	return false
+}
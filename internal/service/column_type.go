@@ -0,0 +1,84 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberPattern SQL数值字面量。Inf、NaN、十六进制和带下划线的写法不是通用的字面量，按字符串处理
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+// TypeKind 列数据类型的大类，用于决定可用的过滤运算符
+type TypeKind int
+
+const (
+	KindUnknown TypeKind = iota
+	KindString
+	KindNumber
+	KindDate
+	KindBool
+)
+
+// ColumnKind 根据数据库列类型判断其大类
+func ColumnKind(dataType string) TypeKind {
+	t := strings.ToLower(dataType)
+	switch {
+	case t == "":
+		return KindUnknown
+	case t == "bool", t == "boolean", t == "tinyint(1)":
+		return KindBool
+	case strings.Contains(t, "char"), strings.Contains(t, "text"),
+		strings.HasPrefix(t, "enum"), strings.HasPrefix(t, "set"), strings.HasPrefix(t, "json"):
+		return KindString
+	case strings.Contains(t, "int"), strings.HasPrefix(t, "decimal"), strings.HasPrefix(t, "numeric"),
+		strings.HasPrefix(t, "float"), strings.HasPrefix(t, "double"), strings.HasPrefix(t, "real"),
+		strings.HasPrefix(t, "bit"):
+		return KindNumber
+	case strings.HasPrefix(t, "date"), strings.HasPrefix(t, "time"), strings.HasPrefix(t, "year"):
+		return KindDate
+	default:
+		return KindUnknown
+	}
+}
+
+// OperatorsForType 返回某种列类型可用的过滤运算符
+func OperatorsForType(dataType string) []FilterOperator {
+	compare := []FilterOperator{OpEqual, OpNotEqual, OpGreater, OpGreaterEqual, OpLess, OpLessEqual}
+	nulls := []FilterOperator{OpIsNull, OpIsNotNull}
+
+	var ops []FilterOperator
+	switch ColumnKind(dataType) {
+	case KindString:
+		ops = []FilterOperator{OpEqual, OpNotEqual, OpLike, OpNotLike, OpILike, OpNotILike, OpIn, OpNotIn}
+	case KindNumber:
+		ops = append(compare, OpBetween, OpIn, OpNotIn)
+	case KindDate:
+		ops = append(compare, OpBetween)
+	case KindBool:
+		ops = []FilterOperator{OpEqual, OpNotEqual}
+	default:
+		ops = append(compare, OpLike, OpNotLike, OpILike, OpNotILike, OpIn, OpNotIn, OpBetween)
+	}
+	return append(ops, nulls...)
+}
+
+// formatLiteral 按MySQL写法把界面输入的值转换为SQL字面量，用于表达式构建
+func formatLiteral(value, dataType string) string {
+	return literal(MySQL, value, dataType)
+}
+
+// literal 把界面输入的值转换为方言的SQL字面量，数值类型不加引号，布尔值使用方言的写法
+func literal(d Dialect, value, dataType string) string {
+	switch ColumnKind(dataType) {
+	case KindNumber:
+		if numberPattern.MatchString(value) {
+			return value
+		}
+	case KindBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return d.BoolLiteral(b)
+		}
+	}
+	return d.QuoteString(value)
+}
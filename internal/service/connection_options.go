@@ -0,0 +1,108 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lowSqlGen/internal/model"
+)
+
+// mysqlConnector 按配置创建 MySQL 连接器，证书通过 tls.Config 直接传给驱动
+func mysqlConnector(config *model.DatabaseConfig) (driver.Connector, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = config.Username
+	cfg.Passwd = config.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(config.Host, config.Port)
+	cfg.DBName = config.Database
+	cfg.Timeout = time.Duration(config.Timeout) * time.Second
+	if config.Charset != "" {
+		cfg.Params = map[string]string{"charset": config.Charset}
+	}
+
+	tlsConfig, err := newTLSConfig(config.TLS, config.Host)
+	if err != nil {
+		return nil, err
+	}
+	cfg.TLS = tlsConfig
+	return mysql.NewConnector(cfg)
+}
+
+// newTLSConfig 按加密方式创建TLS设置，不加密时返回 nil
+func newTLSConfig(opts model.TLSOptions, host string) (*tls.Config, error) {
+	switch opts.Mode {
+	case model.TLSDisabled:
+		return nil, nil
+	case model.TLSRequire, model.TLSVerify:
+	default:
+		return nil, fmt.Errorf("未知的TLS方式 %s", opts.Mode)
+	}
+
+	config := &tls.Config{ServerName: host, InsecureSkipVerify: opts.Mode == model.TLSRequire}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA证书 %s 格式错误", opts.CAFile)
+		}
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取客户端证书失败: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// postgresSSLModes 加密方式对应的 sslmode
+var postgresSSLModes = map[string]string{
+	model.TLSDisabled: "disable",
+	model.TLSRequire:  "require",
+	model.TLSVerify:   "verify-full",
+}
+
+// postgresDSN 按配置生成 PostgreSQL 连接串，未指定数据库时连接 postgres
+func postgresDSN(config *model.DatabaseConfig) string {
+	database := config.Database
+	if database == "" {
+		database = "postgres"
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", postgresSSLModes[config.TLS.Mode])
+	if config.TLS.CAFile != "" {
+		query.Set("sslrootcert", config.TLS.CAFile)
+	}
+	if config.TLS.CertFile != "" {
+		query.Set("sslcert", config.TLS.CertFile)
+		query.Set("sslkey", config.TLS.KeyFile)
+	}
+	if config.Timeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(config.Timeout))
+	}
+	if config.Charset != "" {
+		query.Set("client_encoding", config.Charset)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.Username, config.Password),
+		Host:     net.JoinHostPort(config.Host, config.Port),
+		Path:     "/" + database,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
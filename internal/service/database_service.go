@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/lowSqlGen/internal/model"
@@ -11,34 +13,48 @@ import (
 type DatabaseService interface {
 	GetDatabases() ([]string, error)
 	GetTables(dbName string) ([]string, error)
-	GetColumns(dbName, tableName string) ([]string, error)
+	GetColumns(dbName, tableName string) ([]model.Column, error)
 	Close() error
-	GetColumnType(tableName, columnName string) string
-	GetColumnComment(tableName, columnName string) string
 	GetTableComment(dbName, tableName string) string
+	GetForeignKeys(dbName, tableName string) ([]model.ForeignKey, error)
+	// GetIndexes 获取表的主键、唯一键和普通索引
+	GetIndexes(dbName, tableName string) ([]model.Index, error)
+	// GetObjects 获取数据库中的表、视图、存储过程、函数和触发器
+	GetObjects(dbName string) ([]model.SchemaObject, error)
+	// GetDefinition 获取对象的创建语句
+	GetDefinition(dbName string, object model.SchemaObject) (string, error)
+	LoadSchema(dbName string) (*model.Schema, error)
+	// Query 以 dbName 为当前数据库执行查询，逐行读取结果，ctx 取消时中止查询
+	Query(ctx context.Context, dbName, query string) (*RowIterator, error)
 }
 
+// NewDatabaseService 根据配置中的驱动创建对应的数据库服务
+func NewDatabaseService(config *model.DatabaseConfig) (DatabaseService, error) {
+	switch config.Driver {
+	case model.DriverPostgres:
+		return newPostgresService(config)
+	case model.DriverSQLite:
+		return newSQLiteService(config)
+	default:
+		return newMySQLService(config)
+	}
+}
+
+// databaseService MySQL 数据库服务
 type databaseService struct {
 	db     *sql.DB
 	config *model.DatabaseConfig
 }
 
-func NewDatabaseService(config *model.DatabaseConfig) (DatabaseService, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/",
-		config.Username,
-		config.Password,
-		config.Host,
-		config.Port,
-	)
-
-	db, err := sql.Open("mysql", dsn)
+func newMySQLService(config *model.DatabaseConfig) (DatabaseService, error) {
+	connector, err := mysqlConnector(config)
 	if err != nil {
-		return nil, fmt.Errorf("连接数据库失败: %v", err)
+		return nil, err
 	}
 
-	// 测试连接
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("测试数据库连接失败: %v", err)
+	db, err := openConnector(connector)
+	if err != nil {
+		return nil, err
 	}
 
 	return &databaseService{
@@ -77,83 +93,166 @@ func (s *databaseService) GetDatabases() ([]string, error) {
 	return databases, nil
 }
 
+// GetTables 获取数据库中的表，查询按库名限定，不依赖会话的当前数据库
 func (s *databaseService) GetTables(dbName string) ([]string, error) {
-	// 切换到指定数据库，使用反引号包裹数据库名
-	if _, err := s.db.Exec(fmt.Sprintf("USE `%s`", dbName)); err != nil {
+	query := `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = ?
+		ORDER BY table_name
+	`
+	rows, err := s.db.Query(query, dbName)
+	if err != nil {
 		return nil, err
 	}
+	return scanNames(rows)
+}
 
-	rows, err := s.db.Query("SHOW TABLES")
+// GetColumns 按定义顺序获取列及其类型、约束和注释
+func (s *databaseService) GetColumns(dbName, tableName string) ([]model.Column, error) {
+	query := `
+		SELECT column_name, column_type, is_nullable, column_key, column_default,
+			extra, column_comment, character_set_name
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY ordinal_position
+	`
+	rows, err := s.db.Query(query, dbName, tableName)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanColumns(rows)
+}
 
-	var tables []string
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, err
-		}
-		tables = append(tables, tableName)
-	}
+func (s *databaseService) Close() error {
+	return s.db.Close()
+}
 
-	return tables, nil
+// GetTableComment 获取表注释
+func (s *databaseService) GetTableComment(dbName, tableName string) string {
+	query := `
+		SELECT table_comment 
+		FROM information_schema.tables 
+		WHERE table_schema = ? AND table_name = ?
+	`
+	var comment string
+	err := s.db.QueryRow(query, dbName, tableName).Scan(&comment)
+	if err != nil {
+		return ""
+	}
+	return comment
 }
 
-func (s *databaseService) GetColumns(dbName, tableName string) ([]string, error) {
-	// 切换到指定数据库，使用反引号包裹数据库名
-	if _, err := s.db.Exec(fmt.Sprintf("USE `%s`", dbName)); err != nil {
+// GetForeignKeys 获取引用该表或被该表引用的外键
+func (s *databaseService) GetForeignKeys(dbName, tableName string) ([]model.ForeignKey, error) {
+	query := `
+		SELECT k.constraint_name, k.table_name, k.column_name,
+			k.referenced_table_name, k.referenced_column_name
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints r
+			ON r.constraint_schema = k.constraint_schema
+			AND r.constraint_name = k.constraint_name
+			AND r.table_name = k.table_name
+		WHERE k.table_schema = ? AND (k.table_name = ? OR k.referenced_table_name = ?)
+		ORDER BY k.table_name, k.constraint_name, k.ordinal_position
+	`
+	rows, err := s.db.Query(query, dbName, tableName, tableName)
+	if err != nil {
 		return nil, err
 	}
+	return scanForeignKeys(rows)
+}
 
-	// 获取列信息，使用反引号包裹表名
-	rows, err := s.db.Query(fmt.Sprintf("SHOW COLUMNS FROM `%s`", tableName))
+// GetIndexes 获取表的索引，主键索引的名称为 PRIMARY
+func (s *databaseService) GetIndexes(dbName, tableName string) ([]model.Index, error) {
+	query := `
+		SELECT index_name, table_name, column_name, non_unique = 0, index_name = 'PRIMARY'
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY index_name, seq_in_index
+	`
+	rows, err := s.db.Query(query, dbName, tableName)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanIndexes(rows)
+}
 
-	var columns []string
-	for rows.Next() {
-		var field string
-		var type_, null, key string
-		var default_ sql.NullString
-		var extra string
-		if err := rows.Scan(&field, &type_, &null, &key, &default_, &extra); err != nil {
+// GetObjects 分别查询表和视图、存储过程和函数、触发器，
+// 三个系统表名称列的排序规则不同，不能合并为一个 UNION 查询
+func (s *databaseService) GetObjects(dbName string) ([]model.SchemaObject, error) {
+	queries := []string{
+		`SELECT table_name, IF(table_type = 'VIEW', 'view', 'table'), ''
+		FROM information_schema.tables
+		WHERE table_schema = ?
+		ORDER BY table_name`,
+		`SELECT routine_name, LOWER(routine_type), ''
+		FROM information_schema.routines
+		WHERE routine_schema = ?
+		ORDER BY routine_name`,
+		`SELECT trigger_name, 'trigger', event_object_table
+		FROM information_schema.triggers
+		WHERE trigger_schema = ?
+		ORDER BY trigger_name`,
+	}
+	var objects []model.SchemaObject
+	for _, query := range queries {
+		rows, err := s.db.Query(query, dbName)
+		if err != nil {
+			return nil, err
+		}
+		part, err := scanObjects(rows)
+		if err != nil {
 			return nil, err
 		}
-		columns = append(columns, field)
+		objects = append(objects, part...)
 	}
-
-	return columns, nil
-}
-
-func (s *databaseService) Close() error {
-	return s.db.Close()
-}
-
-func (s *databaseService) GetColumnType(tableName, columnName string) string {
-	// Implementation of GetColumnType method
-	return "" // Placeholder return, actual implementation needed
+	return objects, nil
 }
 
-func (s *databaseService) GetColumnComment(tableName, columnName string) string {
-	// Implementation of GetColumnComment method
-	return "" // Placeholder return, actual implementation needed
+// GetDefinition 以 SHOW CREATE 获取定义，视图和表的定义在第二列，其他对象在第三列
+func (s *databaseService) GetDefinition(dbName string, object model.SchemaObject) (string, error) {
+	column := 2
+	if object.Type == model.ObjectTable || object.Type == model.ObjectView {
+		column = 1
+	}
+	rows, err := s.db.Query(fmt.Sprintf("SHOW CREATE %s %s.%s", strings.ToUpper(object.Type),
+		MySQL.QuoteIdentifier(dbName), MySQL.QuoteIdentifier(object.Name)))
+	if err != nil {
+		return "", err
+	}
+	return scanDefinition(rows, column, object.Name)
 }
 
-// GetTableComment 获取表注释
-func (s *databaseService) GetTableComment(dbName, tableName string) string {
+// LoadSchema 一次查询加载数据库中所有表的注释和列信息
+func (s *databaseService) LoadSchema(dbName string) (*model.Schema, error) {
 	query := `
-		SELECT table_comment 
-		FROM information_schema.tables 
-		WHERE table_schema = ? AND table_name = ?
+		SELECT t.table_name, t.table_comment, c.column_name, c.column_type, c.is_nullable,
+			c.column_key, c.column_default, c.extra, c.column_comment, c.character_set_name
+		FROM information_schema.tables t
+		JOIN information_schema.columns c
+			ON c.table_schema = t.table_schema AND c.table_name = t.table_name
+		WHERE t.table_schema = ?
+		ORDER BY t.table_name, c.ordinal_position
 	`
-	var comment string
-	err := s.db.QueryRow(query, dbName, tableName).Scan(&comment)
+	rows, err := s.db.Query(query, dbName)
 	if err != nil {
-		return ""
+		return nil, err
 	}
-	return comment
+	return scanSchema(dbName, rows)
+}
+
+// Query 在单独的连接上切换到 dbName 后执行查询
+func (s *databaseService) Query(ctx context.Context, dbName, query string) (*RowIterator, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dbName != "" {
+		if _, err := conn.ExecContext(ctx, "USE "+MySQL.QuoteIdentifier(dbName)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return queryRows(ctx, conn, query)
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 各数据库中与 MySQL DATE_FORMAT 说明符对应的写法，不支持的说明符被忽略
+var (
+	postgresDateFormat = map[byte]string{
+		'Y': "YYYY", 'y': "YY", 'm': "MM", 'c': "FMMM", 'd': "DD", 'e': "FMDD",
+		'H': "HH24", 'h': "HH12", 'I': "HH12", 'i': "MI", 's': "SS", 'S': "SS",
+		'p': "AM", 'M': "FMMonth", 'b': "Mon", 'W': "FMDay", 'a': "Dy", 'j': "DDD", 'f': "US",
+	}
+	oracleDateFormat = map[byte]string{
+		'Y': "YYYY", 'y': "YY", 'm': "MM", 'c': "MM", 'd': "DD", 'e': "DD",
+		'H': "HH24", 'h': "HH12", 'I': "HH12", 'i': "MI", 's': "SS", 'S': "SS",
+		'p': "AM", 'M': "Month", 'b': "Mon", 'W': "Day", 'a': "Dy", 'j': "DDD", 'f': "FF6",
+	}
+	sqlServerDateFormat = map[byte]string{
+		'Y': "yyyy", 'y': "yy", 'm': "MM", 'c': "%M", 'd': "dd", 'e': "%d",
+		'H': "HH", 'h': "hh", 'I': "hh", 'i': "mm", 's': "ss", 'S': "ss",
+		'p': "tt", 'M': "MMMM", 'b': "MMM", 'W': "dddd", 'a': "ddd", 'f': "ffffff",
+	}
+	sqliteDateFormat = map[byte]string{
+		'Y': "%Y", 'm': "%m", 'c': "%m", 'd': "%d", 'e': "%d",
+		'H': "%H", 'i': "%M", 's': "%S", 'S': "%S", 'j': "%j",
+	}
+)
+
+// convertDateFormat 把 MySQL 的日期格式转换为其他数据库的写法，
+// 说明符之外的文字经 literal 处理，避免被当作格式字符
+func convertDateFormat(format string, specifiers map[byte]string, literal func(text string) string) string {
+	var b, text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			b.WriteString(literal(text.String()))
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			text.WriteByte(format[i])
+			continue
+		}
+		i++
+		if format[i] == '%' {
+			text.WriteByte('%')
+			continue
+		}
+		flush()
+		b.WriteString(specifiers[format[i]])
+	}
+	flush()
+	return b.String()
+}
+
+// quotePatternText PostgreSQL 和 Oracle 的格式中，字母文字需要放在双引号内
+func quotePatternText(text string) string {
+	if strings.IndexFunc(text, isPatternLetter) < 0 {
+		return text
+	}
+	return `"` + strings.ReplaceAll(text, `"`, `\"`) + `"`
+}
+
+func isPatternLetter(r rune) bool {
+	return unicode.IsLetter(r)
+}
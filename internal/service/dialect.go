@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// Dialect 数据库方言，决定标识符和字面量的写法、分页语法以及各数据库特有的函数
+type Dialect interface {
+	Name() string
+	QuoteIdentifier(name string) string
+	QuoteString(value string) string
+	BoolLiteral(value bool) string
+	// ILike 不区分大小写的模式匹配
+	ILike(column, pattern string, negate bool) string
+	// Limit 返回分页写法：top 紧跟在 SELECT 之后，suffix 位于语句末尾，
+	// ordered 表示语句是否已有 ORDER BY
+	Limit(limit, offset int, ordered bool) (top, suffix string, err error)
+	Concat(parts []string) string
+	// DateFormat 按 MySQL DATE_FORMAT 的格式说明符格式化日期
+	DateFormat(expr, format string) string
+	GroupConcat(expr string) string
+	// SupportsFullJoin 为 false 时 FULL OUTER JOIN 以 UNION 模拟
+	SupportsFullJoin() bool
+	// Placeholder 返回第 n 个参数（从1开始）的占位符
+	Placeholder(n int) string
+}
+
+var (
+	MySQL      Dialect = mysqlDialect{}
+	PostgreSQL Dialect = postgresDialect{}
+	SQLite     Dialect = sqliteDialect{}
+	SQLServer  Dialect = sqlServerDialect{}
+	Oracle     Dialect = oracleDialect{}
+)
+
+// Dialects 返回所有可选的方言
+func Dialects() []Dialect {
+	return []Dialect{MySQL, PostgreSQL, SQLite, SQLServer, Oracle}
+}
+
+// DialectByName 根据名称查找方言，未找到时返回MySQL
+func DialectByName(name string) Dialect {
+	for _, d := range Dialects() {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return MySQL
+}
+
+// DialectFor 返回数据库驱动对应的方言，未知驱动按MySQL处理
+func DialectFor(driver string) Dialect {
+	switch driver {
+	case model.DriverPostgres:
+		return PostgreSQL
+	case model.DriverSQLite:
+		return SQLite
+	default:
+		return MySQL
+	}
+}
+
+// ansiDialect 标准SQL写法，各方言在此基础上覆盖不同之处
+type ansiDialect struct{}
+
+func (ansiDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteString 标准字符串中反斜杠不是转义字符
+func (ansiDialect) QuoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (ansiDialect) BoolLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// ILike 默认排序规则不区分大小写的数据库直接使用 LIKE
+func (ansiDialect) ILike(column, pattern string, negate bool) string {
+	if negate {
+		return fmt.Sprintf("%s NOT LIKE %s", column, pattern)
+	}
+	return fmt.Sprintf("%s LIKE %s", column, pattern)
+}
+
+func (ansiDialect) Limit(limit, offset int, ordered bool) (string, string, error) {
+	suffix, err := limitClause(limit, offset)
+	return "", suffix, err
+}
+
+func (ansiDialect) Concat(parts []string) string {
+	return strings.Join(parts, " || ")
+}
+
+func (ansiDialect) SupportsFullJoin() bool { return true }
+
+func (ansiDialect) Placeholder(int) string { return "?" }
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+type mysqlDialect struct{ ansiDialect }
+
+func (mysqlDialect) Name() string { return "MySQL" }
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) QuoteString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, "'", "''")
+	return "'" + escaped + "'"
+}
+
+func (mysqlDialect) Concat(parts []string) string {
+	return fmt.Sprintf("CONCAT(%s)", strings.Join(parts, ", "))
+}
+
+func (d mysqlDialect) DateFormat(expr, format string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, %s)", expr, d.QuoteString(format))
+}
+
+func (mysqlDialect) GroupConcat(expr string) string {
+	return fmt.Sprintf("GROUP_CONCAT(%s)", expr)
+}
+
+// SupportsFullJoin MySQL 不支持 FULL OUTER JOIN
+func (mysqlDialect) SupportsFullJoin() bool { return false }
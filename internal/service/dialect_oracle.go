@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+)
+
+// oracleDialect 分页使用 12c 起支持的 OFFSET ... FETCH FIRST
+type oracleDialect struct{ ansiDialect }
+
+func (oracleDialect) Name() string { return "Oracle" }
+
+// BoolLiteral Oracle SQL 中没有布尔类型，通常以 NUMBER(1) 存储
+func (oracleDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// ILike Oracle 的 LIKE 区分大小写，两侧统一转换为大写
+func (oracleDialect) ILike(column, pattern string, negate bool) string {
+	if negate {
+		return fmt.Sprintf("UPPER(%s) NOT LIKE UPPER(%s)", column, pattern)
+	}
+	return fmt.Sprintf("UPPER(%s) LIKE UPPER(%s)", column, pattern)
+}
+
+func (oracleDialect) Limit(limit, offset int, ordered bool) (string, string, error) {
+	if _, err := limitClause(limit, offset); err != nil || limit == 0 {
+		return "", "", err
+	}
+	if offset == 0 {
+		return "", fmt.Sprintf("FETCH FIRST %d ROWS ONLY", limit), nil
+	}
+	return "", fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit), nil
+}
+
+func (oracleDialect) Placeholder(n int) string { return fmt.Sprintf(":%d", n) }
+
+func (d oracleDialect) DateFormat(expr, format string) string {
+	return fmt.Sprintf("TO_CHAR(%s, %s)", expr, d.QuoteString(convertDateFormat(format, oracleDateFormat, quotePatternText)))
+}
+
+func (oracleDialect) GroupConcat(expr string) string {
+	return fmt.Sprintf("LISTAGG(%s, ',') WITHIN GROUP (ORDER BY %s)", expr, expr)
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"fmt"
+)
+
+type postgresDialect struct{ ansiDialect }
+
+func (postgresDialect) Name() string { return "PostgreSQL" }
+
+func (postgresDialect) ILike(column, pattern string, negate bool) string {
+	if negate {
+		return fmt.Sprintf("%s NOT ILIKE %s", column, pattern)
+	}
+	return fmt.Sprintf("%s ILIKE %s", column, pattern)
+}
+
+func (d postgresDialect) DateFormat(expr, format string) string {
+	return fmt.Sprintf("to_char(%s, %s)", expr, d.QuoteString(convertDateFormat(format, postgresDateFormat, quotePatternText)))
+}
+
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) GroupConcat(expr string) string {
+	return fmt.Sprintf("STRING_AGG(CAST(%s AS TEXT), ',')", expr)
+}
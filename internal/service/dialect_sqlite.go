@@ -0,0 +1,30 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqliteDialect 3.39 起支持 FULL OUTER JOIN，LIKE 对ASCII字符不区分大小写
+type sqliteDialect struct{ ansiDialect }
+
+func (sqliteDialect) Name() string { return "SQLite" }
+
+// BoolLiteral SQLite 以整数表示布尔值
+func (sqliteDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+func (d sqliteDialect) DateFormat(expr, format string) string {
+	pattern := convertDateFormat(format, sqliteDateFormat, func(text string) string {
+		return strings.ReplaceAll(text, "%", "%%")
+	})
+	return fmt.Sprintf("strftime(%s, %s)", d.QuoteString(pattern), expr)
+}
+
+func (sqliteDialect) GroupConcat(expr string) string {
+	return fmt.Sprintf("GROUP_CONCAT(%s)", expr)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlServerDialect 使用 [ ] 引用标识符，分页使用 TOP 或 OFFSET ... FETCH
+type sqlServerDialect struct{ ansiDialect }
+
+func (sqlServerDialect) Name() string { return "SQL Server" }
+
+func (sqlServerDialect) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// BoolLiteral SQL Server 没有布尔字面量，bit 列使用 1/0
+func (sqlServerDialect) BoolLiteral(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// Limit 没有偏移时使用 TOP，否则使用 OFFSET ... FETCH，后者要求语句有 ORDER BY
+func (sqlServerDialect) Limit(limit, offset int, ordered bool) (string, string, error) {
+	if _, err := limitClause(limit, offset); err != nil || limit == 0 {
+		return "", "", err
+	}
+	if offset == 0 {
+		return fmt.Sprintf("TOP %d", limit), "", nil
+	}
+	suffix := fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit)
+	if !ordered {
+		suffix = "ORDER BY (SELECT NULL) " + suffix
+	}
+	return "", suffix, nil
+}
+
+func (sqlServerDialect) Concat(parts []string) string {
+	return fmt.Sprintf("CONCAT(%s)", strings.Join(parts, ", "))
+}
+
+func (sqlServerDialect) Placeholder(n int) string { return fmt.Sprintf("@p%d", n) }
+
+func (d sqlServerDialect) DateFormat(expr, format string) string {
+	pattern := convertDateFormat(format, sqlServerDateFormat, func(text string) string {
+		var b strings.Builder
+		for _, r := range text {
+			if isPatternLetter(r) || r == '\\' {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	})
+	return fmt.Sprintf("FORMAT(%s, %s)", expr, d.QuoteString(pattern))
+}
+
+func (sqlServerDialect) GroupConcat(expr string) string {
+	return fmt.Sprintf("STRING_AGG(CAST(%s AS NVARCHAR(MAX)), ',')", expr)
+}
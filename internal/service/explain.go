@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// PlanNode 执行计划中的一个步骤
+type PlanNode struct {
+	Label    string
+	Table    string   // 访问的表在语句中的名称，有别名时为别名，不访问表时为空
+	Details  []string // 行数、代价、使用的索引和条件等
+	FullScan bool     // 读取整张表
+	NoIndex  bool     // 按条件过滤或连接时没有可用的索引
+	Children []*PlanNode
+}
+
+// Warning 返回步骤存在的问题，没有问题时为空
+func (n *PlanNode) Warning() string {
+	switch {
+	case n.NoIndex:
+		return "no usable index"
+	case n.FullScan:
+		return "full table scan"
+	default:
+		return ""
+	}
+}
+
+// Walk 按先序遍历所有步骤
+func (n *PlanNode) Walk(fn func(node *PlanNode)) {
+	fn(n)
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// Explain 按驱动执行 EXPLAIN 并把执行计划整理为树
+func Explain(ctx context.Context, svc DatabaseService, driver, dbName, query string) (*PlanNode, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	switch driver {
+	case model.DriverPostgres:
+		doc, err := explainJSON(ctx, svc, dbName, "EXPLAIN (FORMAT JSON) "+query)
+		if err != nil {
+			return nil, err
+		}
+		return postgresPlan(doc)
+	case model.DriverSQLite:
+		return sqlitePlan(ctx, svc, dbName, query)
+	default:
+		doc, err := explainJSON(ctx, svc, dbName, "EXPLAIN FORMAT=JSON "+query)
+		if err != nil {
+			return nil, err
+		}
+		return mysqlPlan(doc)
+	}
+}
+
+// explainJSON 执行返回单个 JSON 文档的 EXPLAIN 语句
+func explainJSON(ctx context.Context, svc DatabaseService, dbName, statement string) (string, error) {
+	it, err := svc.Query(ctx, dbName, statement)
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("EXPLAIN 没有返回执行计划")
+	}
+	doc, ok := it.Row()[0].(string)
+	if !ok {
+		return "", fmt.Errorf("无法识别的执行计划格式")
+	}
+	return doc, nil
+}
+
+// sqlitePlan 读取 EXPLAIN QUERY PLAN 的结果，按 parent 组织为树
+func sqlitePlan(ctx context.Context, svc DatabaseService, dbName, query string) (*PlanNode, error) {
+	it, err := svc.Query(ctx, dbName, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	root := &PlanNode{Label: "QUERY PLAN"}
+	nodes := map[int64]*PlanNode{0: root}
+	for it.Next() {
+		row := it.Row()
+		id, _ := row[0].(int64)
+		parentID, _ := row[1].(int64)
+		detail, _ := row[3].(string)
+		node := &PlanNode{Label: detail}
+		parent := nodes[parentID]
+		if parent == nil {
+			parent = root
+		}
+
+		// SCAN t 为全表扫描，SCAN t USING INDEX 为索引扫描，AUTOMATIC INDEX 表示缺少索引
+		fields := strings.Fields(detail)
+		if len(fields) > 1 && (fields[0] == "SCAN" || fields[0] == "SEARCH") {
+			node.Table = fields[1]
+			// 旧版本写作 SCAN TABLE orders AS o
+			if node.Table == "TABLE" && len(fields) > 2 {
+				node.Table = fields[2]
+			}
+			for i := 2; i+1 < len(fields); i++ {
+				if fields[i] == "AS" {
+					node.Table = fields[i+1]
+				}
+			}
+			node.FullScan = fields[0] == "SCAN" && !strings.Contains(detail, " USING ")
+			node.NoIndex = strings.Contains(detail, "AUTOMATIC") || node.FullScan && accessesTable(parent.Children)
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[id] = node
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// accessesTable 判断同一层中是否已经有访问表的步骤，之后的全表扫描位于连接的内层循环
+func accessesTable(nodes []*PlanNode) bool {
+	for _, node := range nodes {
+		if node.Table != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys 按键名排序，使解析结果稳定
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// mysqlTableDetails 表访问步骤中显示的字段及其说明
+var mysqlTableDetails = []struct{ key, label string }{
+	{"key", "index"},
+	{"rows_examined_per_scan", "rows"},
+	{"filtered", "filtered %"},
+	{"using_join_buffer", "join buffer"},
+	{"attached_condition", "condition"},
+}
+
+// mysqlPlan 解析 EXPLAIN FORMAT=JSON 的结果
+func mysqlPlan(doc string) (*PlanNode, error) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(doc), &obj); err != nil {
+		return nil, fmt.Errorf("无法解析执行计划: %v", err)
+	}
+	block, ok := obj["query_block"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("无法识别的执行计划格式")
+	}
+	return mysqlPlanNode("query_block", block), nil
+}
+
+// mysqlPlanNode 把 JSON 对象转为步骤，嵌套的对象和数组成为子步骤
+func mysqlPlanNode(key string, obj map[string]any) *PlanNode {
+	node := &PlanNode{Label: strings.ReplaceAll(key, "_", " ")}
+	if name, ok := obj["table_name"].(string); ok {
+		mysqlTableNode(node, name, obj)
+	}
+
+	for _, k := range sortedKeys(obj) {
+		switch v := obj[k].(type) {
+		case map[string]any:
+			if k == "cost_info" {
+				if cost, ok := v["query_cost"]; ok {
+					node.Details = append(node.Details, fmt.Sprintf("cost: %v", cost))
+				}
+				continue
+			}
+			node.Children = append(node.Children, mysqlPlanNode(k, v))
+		case []any:
+			// nested_loop 等数组的每一项形如 {"table": {...}}
+			group := &PlanNode{Label: strings.ReplaceAll(k, "_", " ")}
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					group.Children = append(group.Children, mysqlPlanItem(k, m))
+				}
+			}
+			if len(group.Children) > 0 {
+				node.Children = append(node.Children, group)
+			}
+		case bool:
+			if v && strings.HasPrefix(k, "using_") && node.Table == "" {
+				node.Details = append(node.Details, strings.ReplaceAll(k, "_", " "))
+			}
+		}
+	}
+	return node
+}
+
+// mysqlPlanItem 只有一个对象成员的数组项直接使用该成员
+func mysqlPlanItem(key string, item map[string]any) *PlanNode {
+	if len(item) == 1 {
+		for k, v := range item {
+			if m, ok := v.(map[string]any); ok {
+				return mysqlPlanNode(k, m)
+			}
+		}
+	}
+	return mysqlPlanNode(key, item)
+}
+
+// mysqlTableNode 填写表访问步骤。access_type 为 ALL 表示全表扫描，
+// 此时没有 possible_keys 而又有过滤或连接条件，说明缺少索引
+func mysqlTableNode(node *PlanNode, name string, obj map[string]any) {
+	access, _ := obj["access_type"].(string)
+	node.Table = name
+	node.Label = fmt.Sprintf("%s (%s)", name, access)
+	for _, detail := range mysqlTableDetails {
+		if v, ok := obj[detail.key]; ok {
+			node.Details = append(node.Details, fmt.Sprintf("%s: %v", detail.label, v))
+		}
+	}
+	if access == "ALL" {
+		node.FullScan = true
+		_, hasKeys := obj["possible_keys"]
+		_, hasCondition := obj["attached_condition"]
+		_, joinBuffer := obj["using_join_buffer"]
+		node.NoIndex = !hasKeys && (hasCondition || joinBuffer)
+	}
+}
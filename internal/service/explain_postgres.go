@@ -0,0 +1,73 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// postgresDetails 步骤中显示的字段及其说明
+var postgresDetails = []struct{ key, label string }{
+	{"Index Name", "index"},
+	{"Plan Rows", "rows"},
+	{"Total Cost", "cost"},
+	{"Index Cond", "index condition"},
+	{"Hash Cond", "hash condition"},
+	{"Merge Cond", "merge condition"},
+	{"Join Filter", "join filter"},
+	{"Filter", "filter"},
+	{"Sort Key", "sort key"},
+}
+
+// postgresPlan 解析 EXPLAIN (FORMAT JSON) 的结果
+func postgresPlan(doc string) (*PlanNode, error) {
+	var plans []map[string]any
+	if err := json.Unmarshal([]byte(doc), &plans); err != nil {
+		return nil, fmt.Errorf("无法解析执行计划: %v", err)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("EXPLAIN 没有返回执行计划")
+	}
+	plan, ok := plans[0]["Plan"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("无法识别的执行计划格式")
+	}
+	return postgresPlanNode(plan, false), nil
+}
+
+// postgresPlanNode 转换一个步骤。inner 表示位于 Nested Loop 的内层，
+// 内层的 Seq Scan 对外层的每一行都要扫描一次整张表，说明连接列缺少索引
+func postgresPlanNode(plan map[string]any, inner bool) *PlanNode {
+	nodeType, _ := plan["Node Type"].(string)
+	node := &PlanNode{Label: nodeType}
+	if joinType, ok := plan["Join Type"].(string); ok {
+		node.Label += " (" + joinType + ")"
+	}
+	if relation, ok := plan["Relation Name"].(string); ok {
+		node.Table = relation
+		node.Label += " on " + relation
+		if alias, ok := plan["Alias"].(string); ok && alias != relation {
+			node.Table = alias
+			node.Label += " " + alias
+		}
+	}
+	for _, detail := range postgresDetails {
+		if v, ok := plan[detail.key]; ok {
+			node.Details = append(node.Details, fmt.Sprintf("%s: %v", detail.label, v))
+		}
+	}
+	if nodeType == "Seq Scan" {
+		node.FullScan = true
+		_, filtered := plan["Filter"]
+		node.NoIndex = filtered || inner
+	}
+
+	children, _ := plan["Plans"].([]any)
+	for i, child := range children {
+		if m, ok := child.(map[string]any); ok {
+			// Materialize 只缓存内层的结果，不改变内层的扫描方式
+			childInner := nodeType == "Nested Loop" && i == 1 || inner && nodeType == "Materialize"
+			node.Children = append(node.Children, postgresPlanNode(m, childInner))
+		}
+	}
+	return node
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat 导出文件的格式
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "CSV"
+	ExportJSONL  ExportFormat = "JSON Lines"
+	ExportXLSX   ExportFormat = "XLSX"
+	ExportInsert ExportFormat = "SQL INSERT"
+)
+
+// ExportFormats 返回所有可选的导出格式
+func ExportFormats() []ExportFormat {
+	return []ExportFormat{ExportCSV, ExportJSONL, ExportXLSX, ExportInsert}
+}
+
+// Extension 格式对应的文件扩展名
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportJSONL:
+		return ".jsonl"
+	case ExportXLSX:
+		return ".xlsx"
+	case ExportInsert:
+		return ".sql"
+	default:
+		return ".csv"
+	}
+}
+
+// CSV 文件可选的编码，Excel 打开不带 BOM 的 UTF-8 文件时中文会乱码
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF8BOM = "UTF-8 with BOM"
+	EncodingGBK     = "GBK"
+)
+
+// exportProgressStep 每写出多少行报告一次进度
+const exportProgressStep = 1000
+
+// ExportOptions 导出设置，与格式无关的字段被忽略
+type ExportOptions struct {
+	Format    ExportFormat
+	Delimiter rune    // CSV 分隔符，为 0 时使用逗号
+	Encoding  string  // CSV 编码，为空时使用 UTF-8
+	Table     string  // INSERT 语句的目标表，可以带库名，例如 db.table
+	BatchSize int     // 每条 INSERT 语句包含的行数，为 0 时使用 defaultInsertBatch
+	Dialect   Dialect // INSERT 语句的方言，为 nil 时使用MySQL
+}
+
+// RowWriter 按某种格式逐行写出查询结果
+type RowWriter interface {
+	WriteHeader(columns []ResultColumn) error
+	WriteRow(row []any) error
+	// Close 写出缓冲的内容和文件结尾，不关闭底层的 io.Writer
+	Close() error
+}
+
+// NewRowWriter 按导出格式创建 RowWriter
+func NewRowWriter(w io.Writer, opts ExportOptions) (RowWriter, error) {
+	switch opts.Format {
+	case ExportCSV:
+		return newCSVWriter(w, opts)
+	case ExportJSONL:
+		return newJSONLinesWriter(w), nil
+	case ExportXLSX:
+		return newXLSXWriter(w), nil
+	case ExportInsert:
+		return newInsertWriter(w, opts)
+	default:
+		return nil, fmt.Errorf("不支持的导出格式 %s", opts.Format)
+	}
+}
+
+// Export 把迭代器中的行逐行写出，返回写出的行数。progress 可以为 nil
+func Export(it *RowIterator, w io.Writer, opts ExportOptions, progress func(rows int)) (int, error) {
+	writer, err := NewRowWriter(w, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteHeader(it.Columns()); err != nil {
+		return 0, err
+	}
+	count := 0
+	for it.Next() {
+		if err := writer.WriteRow(it.Row()); err != nil {
+			return count, err
+		}
+		count++
+		if progress != nil && count%exportProgressStep == 0 {
+			progress(count)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+// ExportFile 执行查询并把结果写入文件。先写临时文件，成功后再替换，失败或取消时不留下不完整的文件
+func ExportFile(ctx context.Context, svc DatabaseService, dbName, query, path string, opts ExportOptions, progress func(rows int)) (int, error) {
+	it, err := svc.Query(ctx, dbName, query)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	buffered := bufio.NewWriter(tmp)
+	count, err := Export(it, buffered, opts, progress)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, os.Rename(tmp.Name(), path)
+}
+
+// numberText 数值以原样写出，返回 false 表示应按字符串处理。
+// MySQL 的文本协议把数值也返回为字符串，此时按列类型判断
+func numberText(v any, col ResultColumn) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case string:
+		if ColumnKind(col.Type) != KindNumber || strings.ContainsAny(v, "nNiIxX_") {
+			return "", false
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", false
+		}
+		return v, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// csvWriter 按指定的分隔符和编码写出 CSV，NULL 写为空字段
+type csvWriter struct {
+	csv     *csv.Writer
+	encoder io.WriteCloser // 转换编码时的转换器，关闭时写出剩余的内容
+}
+
+func newCSVWriter(w io.Writer, opts ExportOptions) (*csvWriter, error) {
+	writer := &csvWriter{}
+	switch opts.Encoding {
+	case "", EncodingUTF8:
+	case EncodingUTF8BOM:
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+	case EncodingGBK:
+		// GBK 无法表示的字符（例如 emoji）替换为替代字符 0x1A，不中断导出
+		writer.encoder = transform.NewWriter(w, encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder()))
+		w = writer.encoder
+	default:
+		return nil, fmt.Errorf("不支持的编码 %s", opts.Encoding)
+	}
+
+	writer.csv = csv.NewWriter(w)
+	writer.csv.UseCRLF = true
+	if opts.Delimiter != 0 {
+		writer.csv.Comma = opts.Delimiter
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) WriteHeader(columns []ResultColumn) error {
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = col.Name
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) WriteRow(row []any) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		if v != nil {
+			record[i] = FormatValue(v)
+		}
+	}
+	return w.csv.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
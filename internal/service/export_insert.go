@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	defaultInsertBatch   = 100
+	sqlServerInsertBatch = 1000 // SQL Server 一条 VALUES 最多 1000 行
+)
+
+// insertWriter 写出按批次合并的 INSERT 语句，每批一条语句
+type insertWriter struct {
+	w       *bufio.Writer
+	dialect Dialect
+	table   string
+	batch   int
+	pending int // 当前语句中已写出的行数
+	prefix  string
+	columns []ResultColumn
+}
+
+func newInsertWriter(w io.Writer, opts ExportOptions) (*insertWriter, error) {
+	table := strings.TrimSpace(opts.Table)
+	if table == "" {
+		return nil, fmt.Errorf("请填写 INSERT 语句的目标表")
+	}
+	writer := &insertWriter{w: bufio.NewWriter(w), dialect: opts.Dialect, table: table, batch: opts.BatchSize}
+	if writer.dialect == nil {
+		writer.dialect = MySQL
+	}
+	if writer.batch <= 0 {
+		writer.batch = defaultInsertBatch
+	}
+	// Oracle 不支持一条 INSERT 写多行 VALUES
+	switch writer.dialect.(type) {
+	case oracleDialect:
+		writer.batch = 1
+	case sqlServerDialect:
+		if writer.batch > sqlServerInsertBatch {
+			writer.batch = sqlServerInsertBatch
+		}
+	}
+	return writer, nil
+}
+
+func (w *insertWriter) WriteHeader(columns []ResultColumn) error {
+	w.columns = columns
+	var table []string
+	for _, part := range strings.Split(w.table, ".") {
+		table = append(table, w.dialect.QuoteIdentifier(part))
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = w.dialect.QuoteIdentifier(col.Name)
+	}
+	w.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES", strings.Join(table, "."), strings.Join(names, ", "))
+	return nil
+}
+
+func (w *insertWriter) WriteRow(row []any) error {
+	if w.pending == 0 {
+		w.w.WriteString(w.prefix + "\n  (")
+	} else {
+		w.w.WriteString(",\n  (")
+	}
+	for i, v := range row {
+		if i > 0 {
+			w.w.WriteString(", ")
+		}
+		w.w.WriteString(w.literal(v, w.columns[i]))
+	}
+	w.w.WriteString(")")
+
+	w.pending++
+	if w.pending == w.batch {
+		w.pending = 0
+		_, err := w.w.WriteString(";\n")
+		return err
+	}
+	return nil
+}
+
+func (w *insertWriter) Close() error {
+	if w.pending > 0 {
+		w.w.WriteString(";\n")
+	}
+	return w.w.Flush()
+}
+
+// literal 把值写成方言中的字面量
+func (w *insertWriter) literal(v any, col ResultColumn) string {
+	if number, ok := numberText(v, col); ok {
+		return number
+	}
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return w.dialect.BoolLiteral(v)
+	case []byte:
+		return hexLiteral(w.dialect, v)
+	default:
+		return w.dialect.QuoteString(FormatValue(v))
+	}
+}
+
+// hexLiteral 二进制值的字面量
+func hexLiteral(d Dialect, b []byte) string {
+	digits := hex.EncodeToString(b)
+	switch d.(type) {
+	case postgresDialect:
+		return `'\x` + digits + `'::bytea`
+	case sqlServerDialect:
+		return "0x" + digits
+	case oracleDialect:
+		return "HEXTORAW('" + digits + "')"
+	default:
+		return "X'" + digits + "'"
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// jsonLinesWriter 每行写出一个 JSON 对象，键的顺序与结果列一致
+type jsonLinesWriter struct {
+	w       *bufio.Writer
+	columns []ResultColumn
+	keys    [][]byte // 编码后的列名
+}
+
+func newJSONLinesWriter(w io.Writer) *jsonLinesWriter {
+	return &jsonLinesWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *jsonLinesWriter) WriteHeader(columns []ResultColumn) error {
+	w.columns = columns
+	for _, col := range columns {
+		key, err := jsonValue(col.Name)
+		if err != nil {
+			return err
+		}
+		w.keys = append(w.keys, key)
+	}
+	return nil
+}
+
+func (w *jsonLinesWriter) WriteRow(row []any) error {
+	w.w.WriteByte('{')
+	for i, v := range row {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		w.w.Write(w.keys[i])
+		w.w.WriteByte(':')
+
+		var value []byte
+		if number, ok := numberText(v, w.columns[i]); ok {
+			value = []byte(number)
+		} else {
+			var err error
+			switch v := v.(type) {
+			case nil, bool, []byte:
+				value, err = jsonValue(v)
+			default:
+				value, err = jsonValue(FormatValue(v))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		w.w.Write(value)
+	}
+	w.w.WriteByte('}')
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return w.w.Flush()
+}
+
+// jsonValue 编码单个值，不转义 HTML 字符，二进制按 base64 编码
+func jsonValue(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	xlsxMaxRows     = 1048576 // 一个工作表的最大行数，包括表头
+	xlsxMaxCellText = 32767   // 单元格文本的最大长度
+)
+
+// xlsxParts 工作簿中除工作表以外的固定部分，按写入顺序排列
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Result" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter 写出只有一个工作表的 XLSX 文件。工作表逐行写入 zip，
+// 文本使用内联字符串，不需要在内存中保留共享字符串表
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []ResultColumn
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w)}
+}
+
+func (w *xlsxWriter) WriteHeader(columns []ResultColumn) error {
+	for _, part := range xlsxParts {
+		entry, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return err
+		}
+	}
+	entry, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(entry)
+	w.columns = columns
+	w.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return w.writeRow(header, nil)
+}
+
+func (w *xlsxWriter) WriteRow(row []any) error {
+	return w.writeRow(row, w.columns)
+}
+
+// writeRow 写出一行，columns 为 nil 时所有值都按文本写出
+func (w *xlsxWriter) writeRow(row []any, columns []ResultColumn) error {
+	if w.rows == xlsxMaxRows {
+		return fmt.Errorf("结果超过 XLSX 的最大行数 %d，请使用 CSV 导出", xlsxMaxRows-1)
+	}
+	w.rows++
+
+	w.sheet.WriteString("<row>")
+	for i, v := range row {
+		if v == nil {
+			w.sheet.WriteString("<c/>")
+			continue
+		}
+		if b, ok := v.(bool); ok {
+			value := "0"
+			if b {
+				value = "1"
+			}
+			w.sheet.WriteString(`<c t="b"><v>` + value + `</v></c>`)
+			continue
+		}
+		if columns != nil {
+			if number, ok := numberText(v, columns[i]); ok {
+				w.sheet.WriteString("<c><v>" + number + "</v></c>")
+				continue
+			}
+		}
+		text := FormatValue(v)
+		if utf8.RuneCountInString(text) > xlsxMaxCellText {
+			text = string([]rune(text)[:xlsxMaxCellText])
+		}
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(w.sheet, []byte(text)); err != nil {
+			return err
+		}
+		w.sheet.WriteString("</t></is></c>")
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString("</sheetData></worksheet>")
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
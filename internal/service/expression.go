@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern 表达式中引用所属表列的占位符，例如 {first_name}
+var placeholderPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// aliasPattern 合法的列别名
+var aliasPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*$`)
+
+// CaseBranch CASE WHEN 表达式中的一个分支
+type CaseBranch struct {
+	Column   string
+	DataType string
+	Operator FilterOperator
+	Value    string
+	Result   string
+}
+
+// Placeholder 返回引用列的占位符
+func Placeholder(column string) string {
+	return "{" + column + "}"
+}
+
+// ConcatExpression 生成用分隔符拼接多列的表达式
+func ConcatExpression(columns []string, separator string) string {
+	var parts []string
+	for i, col := range columns {
+		if i > 0 && separator != "" {
+			parts = append(parts, formatLiteral(separator, ""))
+		}
+		parts = append(parts, Placeholder(col))
+	}
+	return fmt.Sprintf("CONCAT(%s)", strings.Join(parts, ", "))
+}
+
+// DateFormatExpression 生成日期格式化表达式
+func DateFormatExpression(column, format string) string {
+	return fmt.Sprintf("DATE_FORMAT(%s, %s)", Placeholder(column), formatLiteral(format, ""))
+}
+
+// CaseExpression 生成 CASE WHEN 表达式，elseValue 为空时省略 ELSE 分支
+func CaseExpression(branches []CaseBranch, elseValue string) (string, error) {
+	if len(branches) == 0 {
+		return "", fmt.Errorf("CASE 表达式至少需要一个 WHEN 分支")
+	}
+	var b strings.Builder
+	b.WriteString("CASE")
+	for _, branch := range branches {
+		cond := &Condition{
+			Column:   branch.Column,
+			DataType: branch.DataType,
+			Operator: branch.Operator,
+			Values:   []string{branch.Value},
+		}
+		if branch.Operator == OpIsNull || branch.Operator == OpIsNotNull {
+			cond.Values = nil
+		}
+		when, err := cond.compare(RawExpr{SQL: Placeholder(branch.Column)})
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, " WHEN %s THEN %s", NewRenderer(MySQL).Predicate(when), formatLiteral(branch.Result, ""))
+	}
+	if elseValue != "" {
+		fmt.Fprintf(&b, " ELSE %s", formatLiteral(elseValue, ""))
+	}
+	b.WriteString(" END")
+	return b.String(), nil
+}
+
+// expandExpression 把表达式中的占位符替换为限定后的列引用，
+// 字符串和引用的标识符中的 {xxx} 是用户数据，原样保留
+func expandExpression(expr string, qualify func(column string) string) string {
+	replace := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+			return qualify(strings.TrimSpace(match[1 : len(match)-1]))
+		})
+	}
+
+	var b strings.Builder
+	start := 0 // 尚未输出的非引用部分的起点
+	for i := 0; i < len(expr); {
+		end := quotedEnd(expr, i)
+		if end == i {
+			i++
+			continue
+		}
+		b.WriteString(replace(expr[start:i]))
+		b.WriteString(expr[i:end])
+		start, i = end, end
+	}
+	b.WriteString(replace(expr[start:]))
+	return b.String()
+}
+
+// ValidateAlias 检查别名是否为合法标识符。表别名和列别名输出时总是加引号，
+// 因此 order、user 这样的关键字也可以作为别名
+func ValidateAlias(alias string) error {
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("别名 %q 不是合法的标识符", alias)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias string
+		valid bool
+	}{
+		{"total", true},
+		{"_n2", true},
+		{"金额", true},
+		{"order", true},
+		{"group", true},
+		{"", false},
+		{"2nd", false},
+		{"a b", false},
+		{`a"b`, false},
+		{"a.b", false},
+	}
+	for _, tt := range tests {
+		err := ValidateAlias(tt.alias)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateAlias(%q) = %v, want valid=%v", tt.alias, err, tt.valid)
+		}
+	}
+}
+
+// 关键字作为表别名和列别名时在所有方言中都加引号
+func TestReservedWordAliases(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{MySQL, "SELECT `order`.`id` AS `group` FROM `orders` `order` INNER JOIN `users` `user` ON `order`.`user_id` = `user`.`id`;"},
+		{PostgreSQL, `SELECT "order"."id" AS "group" FROM "orders" "order" INNER JOIN "users" "user" ON "order"."user_id" = "user"."id";`},
+		{SQLServer, "SELECT [order].[id] AS [group] FROM [orders] [order] INNER JOIN [users] [user] ON [order].[user_id] = [user].[id];"},
+	}
+	for _, tt := range tests {
+		g := NewSQLGenerator()
+		g.SetDialect(tt.dialect)
+		g.AddTableInstance(TableInstance{ID: "orders", Table: "orders", Alias: "order"})
+		g.AddTableInstance(TableInstance{ID: "users", Table: "users", Alias: "user"})
+		g.SetMainTable("orders")
+		g.AddSelectedColumn(SelectColumn{Table: "orders", Name: "id", Alias: "group"})
+		g.AddJoin(JoinInfo{SourceInstance: "orders", TargetInstance: "users", SourceTable: "orders", TargetTable: "users", JoinType: InnerJoin,
+			Conditions: []JoinCondition{{Left: ColumnRef{"orders", "user_id"}, Operator: OpEqual, Right: ColumnRef{"users", "id"}}}})
+		got, err := g.GenerateSQL()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dialect.Name(), err)
+		}
+		if strings.TrimSpace(got) != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+// 只有标准的数值写法不加引号，其余输入按字符串处理
+func TestNumberLiteral(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"42", "42"},
+		{"-3.5", "-3.5"},
+		{"+.5", "+.5"},
+		{"1.", "1."},
+		{"1e10", "1e10"},
+		{"2.5E-3", "2.5E-3"},
+		{"Inf", "'Inf'"},
+		{"-Infinity", "'-Infinity'"},
+		{"NaN", "'NaN'"},
+		{"0x1p3", "'0x1p3'"},
+		{"0x10", "'0x10'"},
+		{"1_000", "'1_000'"},
+		{"", "''"},
+		{" 1", "' 1'"},
+		{"1e", "'1e'"},
+	}
+	for _, tt := range tests {
+		if got := literal(PostgreSQL, tt.value, "int"); got != tt.want {
+			t.Errorf("literal(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"fmt"
+)
+
+// FilterOperator 过滤条件运算符
+type FilterOperator string
+
+const (
+	OpEqual        FilterOperator = "="
+	OpNotEqual     FilterOperator = "<>"
+	OpGreater      FilterOperator = ">"
+	OpGreaterEqual FilterOperator = ">="
+	OpLess         FilterOperator = "<"
+	OpLessEqual    FilterOperator = "<="
+	OpLike         FilterOperator = "LIKE"
+	OpNotLike      FilterOperator = "NOT LIKE"
+	OpILike        FilterOperator = "ILIKE" // 不区分大小写的 LIKE
+	OpNotILike     FilterOperator = "NOT ILIKE"
+	OpIn           FilterOperator = "IN"
+	OpNotIn        FilterOperator = "NOT IN"
+	OpBetween      FilterOperator = "BETWEEN"
+	OpIsNull       FilterOperator = "IS NULL"
+	OpIsNotNull    FilterOperator = "IS NOT NULL"
+)
+
+// LogicOperator 条件组的组合方式
+type LogicOperator string
+
+const (
+	LogicAnd LogicOperator = "AND"
+	LogicOr  LogicOperator = "OR"
+)
+
+// Condition 单个过滤条件
+type Condition struct {
+	Table     string         `json:"table"`
+	Column    string         `json:"column"`
+	DataType  string         `json:"dataType,omitempty"`
+	Aggregate AggregateFunc  `json:"aggregate,omitempty"` // 仅用于 HAVING 条件
+	Operator  FilterOperator `json:"operator"`
+	Values    []string       `json:"values,omitempty"`    // 比较值，BETWEEN 需要两个，IN 可以有多个
+	Parameter bool           `json:"parameter,omitempty"` // 使用参数占位符代替字面值
+}
+
+// FilterGroup 过滤条件组，组内可以继续嵌套子组
+type FilterGroup struct {
+	Logic      LogicOperator  `json:"logic"`
+	Conditions []*Condition   `json:"conditions,omitempty"`
+	Groups     []*FilterGroup `json:"groups,omitempty"`
+}
+
+// NewFilterGroup 创建一个空的条件组
+func NewFilterGroup(logic LogicOperator) *FilterGroup {
+	return &FilterGroup{Logic: logic}
+}
+
+// IsEmpty 条件组及其子组中是否没有任何条件
+func (f *FilterGroup) IsEmpty() bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Conditions) > 0 {
+		return false
+	}
+	for _, group := range f.Groups {
+		if !group.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk 依次访问条件组及其子组中的所有条件
+func (f *FilterGroup) Walk(visit func(cond *Condition)) {
+	if f == nil {
+		return
+	}
+	for _, cond := range f.Conditions {
+		visit(cond)
+	}
+	for _, group := range f.Groups {
+		group.Walk(visit)
+	}
+}
+
+// columnResolver 把表实例和列名转换为语法树中的列引用（通常是 别名.列名）
+type columnResolver func(table, column string) (Expr, error)
+
+// predicate 构建条件组的语法树节点，条件组为空时返回 nil
+func (f *FilterGroup) predicate(resolve columnResolver) (Predicate, error) {
+	if f.IsEmpty() {
+		return nil, nil
+	}
+
+	var items []Predicate
+	for _, cond := range f.Conditions {
+		column, err := resolve(cond.Table, cond.Column)
+		if err != nil {
+			return nil, err
+		}
+		item, err := cond.predicate(column)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	for _, group := range f.Groups {
+		if group.IsEmpty() {
+			continue
+		}
+		item, err := group.predicate(resolve)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	logic := f.Logic
+	if logic == "" {
+		logic = LogicAnd
+	}
+	return Logical{Op: logic, Items: items}, nil
+}
+
+// String 以表名限定列名的形式描述条件，用于界面显示
+func (c *Condition) String() string {
+	column := c.Table + "." + c.Column
+	pred, err := c.predicate(RawExpr{SQL: column})
+	if err != nil {
+		return fmt.Sprintf("%s %s ?", c.Aggregate.Apply(column), c.Operator)
+	}
+	return NewRenderer(MySQL).Predicate(pred)
+}
+
+// predicate 以已限定的列引用构建条件，列上的聚合函数在此应用
+func (c *Condition) predicate(column Expr) (Predicate, error) {
+	return c.compare(aggregateExpr(c.Aggregate, column))
+}
+
+// compare 以给定的表达式作为左操作数构建条件
+func (c *Condition) compare(left Expr) (Predicate, error) {
+	count := 1
+	switch c.Operator {
+	case OpIsNull, OpIsNotNull:
+		return Comparison{Left: left, Operator: c.Operator}, nil
+	case OpBetween:
+		count = 2
+	case OpIn, OpNotIn:
+		count = -1
+	case "":
+		return nil, fmt.Errorf("条件 %s.%s 未选择运算符", c.Table, c.Column)
+	}
+
+	values, err := c.operands(count)
+	if err != nil {
+		return nil, err
+	}
+	return Comparison{Left: left, Operator: c.Operator, Right: values}, nil
+}
+
+// operands 返回比较值节点，count 为 -1 表示至少一个
+func (c *Condition) operands(count int) ([]Expr, error) {
+	if c.Parameter {
+		n := count
+		if n < 0 {
+			n = len(c.Values)
+			if n == 0 {
+				n = 1
+			}
+		}
+		placeholders := make([]Expr, n)
+		for i := range placeholders {
+			placeholders[i] = ParamExpr{}
+		}
+		return placeholders, nil
+	}
+
+	if (count < 0 && len(c.Values) == 0) || (count > 0 && len(c.Values) != count) {
+		return nil, fmt.Errorf("条件 %s.%s %s 的比较值数量不正确", c.Table, c.Column, c.Operator)
+	}
+
+	operands := make([]Expr, len(c.Values))
+	for i, value := range c.Values {
+		operands[i] = LiteralExpr{Value: value, DataType: c.Aggregate.ResultType(c.DataType)}
+	}
+	return operands, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// translateFunctions 把表达式中按MySQL写法构建的 CONCAT 和 DATE_FORMAT 调用
+// 转换为方言的写法，其余内容原样保留
+func translateFunctions(expr string, d Dialect) string {
+	var b strings.Builder
+	for i := 0; i < len(expr); {
+		// 跳过字符串和引用的标识符
+		if end := quotedEnd(expr, i); end > i {
+			b.WriteString(expr[i:end])
+			i = end
+			continue
+		}
+		if !isIdentByte(expr[i]) {
+			b.WriteByte(expr[i])
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(expr) && isIdentByte(expr[i]) {
+			i++
+		}
+		name := strings.ToUpper(expr[start:i])
+		open := i
+		for open < len(expr) && expr[open] == ' ' {
+			open++
+		}
+		if (name != "CONCAT" && name != "DATE_FORMAT") || open == len(expr) || expr[open] != '(' ||
+			(start > 0 && expr[start-1] == '.') {
+			b.WriteString(expr[start:i])
+			continue
+		}
+
+		args, end, ok := splitArguments(expr, open)
+		if !ok {
+			b.WriteString(expr[start:i])
+			continue
+		}
+		for j := range args {
+			args[j] = translateFunctions(args[j], d)
+		}
+		b.WriteString(translateCall(expr[start:end], name, args, d))
+		i = end
+	}
+	return b.String()
+}
+
+// translateCall 生成函数调用的方言写法，无法转换时保留原调用
+func translateCall(original, name string, args []string, d Dialect) string {
+	switch name {
+	case "CONCAT":
+		return d.Concat(args)
+	case "DATE_FORMAT":
+		if len(args) == 2 {
+			if format, ok := unquoteLiteral(args[1]); ok {
+				return d.DateFormat(args[0], format)
+			}
+		}
+	}
+	return original
+}
+
+// splitArguments 拆分从 open 处的左括号开始的参数列表，返回参数和右括号之后的位置
+func splitArguments(expr string, open int) ([]string, int, bool) {
+	var args []string
+	depth := 0
+	argStart := open + 1
+	for i := open; i < len(expr); {
+		if end := quotedEnd(expr, i); end > i {
+			i = end
+			continue
+		}
+		switch expr[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(expr[argStart:i]))
+				return args, i + 1, true
+			}
+		case ',':
+			if depth == 1 {
+				args = append(args, strings.TrimSpace(expr[argStart:i]))
+				argStart = i + 1
+			}
+		}
+		i++
+	}
+	return nil, 0, false
+}
+
+// quotedEnd 如果 i 处是字符串或引用标识符的开始，返回其结束之后的位置，否则返回 i
+func quotedEnd(expr string, i int) int {
+	closing := map[byte]byte{'\'': '\'', '"': '"', '`': '`', '[': ']'}[expr[i]]
+	if closing == 0 {
+		return i
+	}
+	for j := i + 1; j < len(expr); j++ {
+		switch {
+		case expr[j] == '\\' && closing == '\'':
+			j++
+		case expr[j] == closing:
+			// 连续两个结束符表示转义
+			if j+1 < len(expr) && expr[j+1] == closing && closing != ']' {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(expr)
+}
+
+// unquoteLiteral 解析表达式构建器生成的字符串字面量
+func unquoteLiteral(text string) (string, bool) {
+	if len(text) < 2 || text[0] != '\'' || text[len(text)-1] != '\'' {
+		return "", false
+	}
+	var b strings.Builder
+	body := text[1 : len(text)-1]
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && i+1 < len(body):
+			i++
+		case body[i] == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			i++
+		case body[i] == '\'':
+			return "", false
+		}
+		b.WriteByte(body[i])
+	}
+	return b.String(), true
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
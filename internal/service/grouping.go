@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+)
+
+// hasAggregate 选中的列或HAVING条件中是否使用了聚合函数
+func (g *SQLGenerator) hasAggregate() bool {
+	for _, col := range g.selectedColumns {
+		if col.Aggregate != AggNone {
+			return true
+		}
+	}
+
+	found := false
+	g.having.Walk(func(cond *Condition) {
+		if cond.Aggregate != AggNone {
+			found = true
+		}
+	})
+	return found
+}
+
+// isGrouped 列是否出现在分组列表中（即被选中且未聚合）
+func (g *SQLGenerator) isGrouped(tableName, column string) bool {
+	for _, col := range g.selectedColumns {
+		if col.Table == tableName && col.Name == column && col.Aggregate == AggNone {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGrouping 检查WHERE中没有聚合，HAVING中未聚合的列都已分组
+func (g *SQLGenerator) validateGrouping() error {
+	var err error
+	g.where.Walk(func(cond *Condition) {
+		if err == nil && cond.Aggregate != AggNone {
+			err = fmt.Errorf("WHERE 条件中不能使用聚合函数: %s", cond)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if g.having.IsEmpty() {
+		return nil
+	}
+	if !g.hasAggregate() {
+		return fmt.Errorf("HAVING 条件需要配合聚合函数使用")
+	}
+	g.having.Walk(func(cond *Condition) {
+		if err == nil && cond.Aggregate == AggNone && !g.isGrouped(cond.Table, cond.Column) {
+			err = fmt.Errorf("HAVING 条件中的列 %s.%s 既未聚合也未分组", cond.Table, cond.Column)
+		}
+	})
+	return err
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+)
+
+// ColumnRef 表实例中的一列
+type ColumnRef struct {
+	Instance string `json:"instance"`
+	Column   string `json:"column"`
+}
+
+// JoinCondition ON 子句中两个表实例列之间的比较，
+// 列通过实例ID引用，因此连接方向反转时条件无需改变
+type JoinCondition struct {
+	Left     ColumnRef      `json:"left"`
+	Operator FilterOperator `json:"operator"`
+	Right    ColumnRef      `json:"right"`
+	Right2   ColumnRef      `json:"right2"` // BETWEEN 的上界
+}
+
+// JoinOperators 返回ON条件中可用的列比较运算符
+func JoinOperators() []FilterOperator {
+	return []FilterOperator{OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual, OpBetween, OpLike}
+}
+
+// String 以实例ID限定列名的形式描述条件，用于界面显示
+func (c JoinCondition) String() string {
+	pred, _ := c.predicate(func(instance, column string) (Expr, error) {
+		return RawExpr{SQL: instance + "." + column}, nil
+	})
+	if pred == nil {
+		return ""
+	}
+	return NewRenderer(MySQL).Predicate(pred)
+}
+
+// predicate 构建条件的语法树节点，未选择运算符时按等值比较
+func (c JoinCondition) predicate(resolve columnResolver) (Predicate, error) {
+	column := func(ref ColumnRef) (Expr, error) {
+		if ref.Column == "" {
+			return nil, fmt.Errorf("连接条件缺少列")
+		}
+		return resolve(ref.Instance, ref.Column)
+	}
+
+	left, err := column(c.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := column(c.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Operator {
+	case OpBetween:
+		upper, err := column(c.Right2)
+		if err != nil {
+			return nil, err
+		}
+		return Comparison{Left: left, Operator: OpBetween, Right: []Expr{right, upper}}, nil
+	case "":
+		return Comparison{Left: left, Operator: OpEqual, Right: []Expr{right}}, nil
+	default:
+		return Comparison{Left: left, Operator: c.Operator, Right: []Expr{right}}, nil
+	}
+}
+
+// on 构建连接的ON条件：列比较条件与字面量条件以 AND 组合，没有条件时返回 nil
+func (j JoinInfo) on(resolve columnResolver) (Predicate, error) {
+	var items []Predicate
+	for _, cond := range j.Conditions {
+		item, err := cond.predicate(resolve)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	for _, filter := range j.Filters {
+		column, err := resolve(filter.Table, filter.Column)
+		if err != nil {
+			return nil, err
+		}
+		item, err := filter.predicate(column)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 && j.JoinType.NeedsCondition() {
+		return nil, fmt.Errorf("表 %s 和 %s 之间的连接缺少ON条件", j.SourceInstance, j.TargetInstance)
+	}
+	return and(items), nil
+}
+
+// Summary 连接条件的简短描述，条件较多时只显示第一个和剩余数量
+func (j JoinInfo) Summary() string {
+	var parts []string
+	for _, cond := range j.Conditions {
+		parts = append(parts, cond.String())
+	}
+	for _, filter := range j.Filters {
+		parts = append(parts, filter.String())
+	}
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return fmt.Sprintf("%s AND +%d", parts[0], len(parts)-1)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+)
+
+// Reverse 交换连接的两侧，LEFT/RIGHT 随之互换，保证语义不变；
+// 条件中的列按实例ID引用，不需要调整
+func (j JoinInfo) Reverse() JoinInfo {
+	reversed := JoinInfo{
+		SourceInstance: j.TargetInstance,
+		TargetInstance: j.SourceInstance,
+		SourceTable:    j.TargetTable,
+		TargetTable:    j.SourceTable,
+		Conditions:     j.Conditions,
+		Filters:        j.Filters,
+		JoinType:       j.JoinType,
+	}
+	switch j.JoinType {
+	case LeftJoin:
+		reversed.JoinType = RightJoin
+	case RightJoin:
+		reversed.JoinType = LeftJoin
+	}
+	return reversed
+}
+
+// orderJoins 从主表实例出发排列连接，使每个连接引入一个新实例；
+// 目标实例已在查询中的连接会被反转，未与主表相连的实例会返回错误
+func orderJoins(mainTable string, tables []string, joins []JoinInfo) ([]JoinInfo, error) {
+	joined := map[string]bool{mainTable: true}
+	used := make([]bool, len(joins))
+	var ordered []JoinInfo
+
+	for progress := true; progress; {
+		progress = false
+		for i, join := range joins {
+			if used[i] {
+				continue
+			}
+			switch {
+			case joined[join.SourceInstance] && joined[join.TargetInstance]:
+				return nil, fmt.Errorf("表 %s 和 %s 之间的连接形成了环", join.SourceInstance, join.TargetInstance)
+			case joined[join.SourceInstance]:
+				ordered = append(ordered, join)
+			case joined[join.TargetInstance]:
+				ordered = append(ordered, join.Reverse())
+			default:
+				continue
+			}
+			used[i] = true
+			joined[join.SourceInstance] = true
+			joined[join.TargetInstance] = true
+			progress = true
+		}
+	}
+
+	for _, table := range tables {
+		if !joined[table] {
+			return nil, fmt.Errorf("表 %s 没有与主表 %s 连接", table, mainTable)
+		}
+	}
+	return ordered, nil
+}
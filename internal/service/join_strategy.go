@@ -0,0 +1,296 @@
+package service
+
+import (
+	"fmt"
+)
+
+// JoinType 表连接类型
+type JoinType string
+
+const (
+	InnerJoin JoinType = "INNER JOIN"
+	LeftJoin  JoinType = "LEFT JOIN"
+	RightJoin JoinType = "RIGHT JOIN"
+	FullJoin  JoinType = "FULL OUTER JOIN"
+	CrossJoin JoinType = "CROSS JOIN"
+)
+
+// JoinTypes 返回所有可选的连接类型
+func JoinTypes() []JoinType {
+	return []JoinType{InnerJoin, LeftJoin, RightJoin, FullJoin, CrossJoin}
+}
+
+// NeedsCondition 连接是否需要ON条件
+func (t JoinType) NeedsCondition() bool {
+	return t != CrossJoin
+}
+
+// JoinStrategy 使用策略模式把连接加入查询语法树，
+// table 为目标表及其别名，on 为已构建的ON条件，可能为空
+type JoinStrategy interface {
+	Apply(query *Select, table TableRef, on Predicate)
+}
+
+// onJoinStrategy 带ON条件的连接的公共实现
+type onJoinStrategy struct {
+	joinType JoinType
+}
+
+func (s *onJoinStrategy) Apply(query *Select, table TableRef, on Predicate) {
+	query.Joins = append(query.Joins, Join{Type: s.joinType, Table: table, On: on})
+}
+
+type InnerJoinStrategy struct{ onJoinStrategy }
+
+type LeftJoinStrategy struct{ onJoinStrategy }
+
+type RightJoinStrategy struct{ onJoinStrategy }
+
+type FullJoinStrategy struct{ onJoinStrategy }
+
+// CrossJoinStrategy 笛卡尔积。PostgreSQL、SQL Server 和 Oracle 不允许 CROSS JOIN 带ON条件，
+// 存在条件时输出等价的 INNER JOIN
+type CrossJoinStrategy struct{}
+
+func (s *CrossJoinStrategy) Apply(query *Select, table TableRef, on Predicate) {
+	joinType := CrossJoin
+	if on != nil {
+		joinType = InnerJoin
+	}
+	query.Joins = append(query.Joins, Join{Type: joinType, Table: table, On: on})
+}
+
+// NewJoinStrategy 根据连接类型创建对应的策略，未知类型按LEFT JOIN处理
+func NewJoinStrategy(joinType JoinType) JoinStrategy {
+	switch joinType {
+	case InnerJoin:
+		return &InnerJoinStrategy{onJoinStrategy{joinType: InnerJoin}}
+	case RightJoin:
+		return &RightJoinStrategy{onJoinStrategy{joinType: RightJoin}}
+	case FullJoin:
+		return &FullJoinStrategy{onJoinStrategy{joinType: FullJoin}}
+	case CrossJoin:
+		return &CrossJoinStrategy{}
+	default:
+		return &LeftJoinStrategy{onJoinStrategy{joinType: LeftJoin}}
+	}
+}
+
+// hasJoin 查询中是否包含指定类型的连接
+func (s *Select) hasJoin(joinType JoinType) bool {
+	for _, join := range s.Joins {
+		if join.Type == joinType {
+			return true
+		}
+	}
+	return false
+}
+
+// fullJoinAlias 模拟 FULL OUTER JOIN 时派生表的别名
+const fullJoinAlias = "full_join"
+
+// emulateFullJoin 把含 FULL OUTER JOIN 的查询改写为派生表：
+// (A LEFT JOIN B ... UNION ALL A RIGHT JOIN B ... WHERE <A 的连接列> IS NULL)。
+// UNION ALL 保留真实的重复行，第二部分只取左侧没有匹配的行；
+// 条件、分组、排序和分页都在外层对合并后的结果计算，参数也只出现一次
+func emulateFullJoin(s *Select) (*Select, error) {
+	full := -1
+	for i, join := range s.Joins {
+		switch {
+		case join.Type == FullJoin && full >= 0:
+			return nil, fmt.Errorf("只能模拟一个 FULL OUTER JOIN")
+		case join.Type == FullJoin:
+			full = i
+		case join.Type == RightJoin && full >= 0:
+			return nil, fmt.Errorf("无法模拟 FULL OUTER JOIN 之后的 RIGHT JOIN")
+		}
+	}
+	key, ok := leftJoinKey(s, full)
+	if !ok {
+		return nil, fmt.Errorf("无法模拟 FULL OUTER JOIN：ON 条件中没有左侧表的列")
+	}
+
+	d := &derivedColumns{names: make(map[ColumnExpr]string)}
+	outer := &Select{
+		Distinct: s.Distinct,
+		Limit:    s.Limit,
+		Offset:   s.Offset,
+	}
+	for _, item := range s.Columns {
+		expr, err := d.expr(item.Expr)
+		if err != nil {
+			return nil, err
+		}
+		// 派生表的列名为 c1、c2...，未设置别名的列保留原来的列名
+		alias := item.Alias
+		if col, ok := item.Expr.(ColumnExpr); ok && alias == "" {
+			alias = col.Column
+		}
+		outer.Columns = append(outer.Columns, SelectItem{Expr: expr, Alias: alias})
+	}
+	var err error
+	if outer.Where, err = d.predicate(s.Where); err != nil {
+		return nil, err
+	}
+	for _, expr := range s.GroupBy {
+		group, err := d.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		outer.GroupBy = append(outer.GroupBy, group)
+	}
+	if outer.Having, err = d.predicate(s.Having); err != nil {
+		return nil, err
+	}
+	for _, item := range s.OrderBy {
+		expr, err := d.expr(item.Expr)
+		if err != nil {
+			return nil, err
+		}
+		outer.OrderBy = append(outer.OrderBy, OrderBy{Expr: expr, Direction: item.Direction})
+	}
+
+	outer.From = TableRef{Alias: fullJoinAlias, Derived: &Union{
+		All: true,
+		Selects: []*Select{
+			d.half(s, full, LeftJoin, nil),
+			d.half(s, full, RightJoin, Comparison{Left: key, Operator: OpIsNull}),
+		},
+	}}
+	return outer, nil
+}
+
+// leftJoinKey 返回 FULL JOIN 的ON条件中左侧表的一列。该列参与的比较在连接成功时必然不为NULL，
+// 因此 RIGHT JOIN 的结果中该列为NULL的行就是左侧没有匹配的行
+func leftJoinKey(s *Select, full int) (ColumnExpr, bool) {
+	left := map[string]bool{refName(s.From): true}
+	for _, join := range s.Joins[:full] {
+		left[refName(join.Table)] = true
+	}
+
+	var find func(pred Predicate) (ColumnExpr, bool)
+	find = func(pred Predicate) (ColumnExpr, bool) {
+		switch p := pred.(type) {
+		case Comparison:
+			if p.Operator == OpIsNull || p.Operator == OpIsNotNull {
+				break
+			}
+			for _, expr := range append([]Expr{p.Left}, p.Right...) {
+				if col, ok := expr.(ColumnExpr); ok && left[col.Qualifier] {
+					return col, true
+				}
+			}
+		case Logical:
+			// OR 中的单个条件不能保证列不为NULL
+			if p.Op == LogicOr && len(p.Items) > 1 {
+				break
+			}
+			for _, item := range p.Items {
+				if col, ok := find(item); ok {
+					return col, true
+				}
+			}
+		}
+		return ColumnExpr{}, false
+	}
+	return find(s.Joins[full].On)
+}
+
+// refName 语句中引用表的名称，有别名时为别名
+func refName(ref TableRef) string {
+	if ref.Alias != "" {
+		return ref.Alias
+	}
+	return ref.Table
+}
+
+// derivedColumns 派生表输出的列，外层按 c1、c2... 引用
+type derivedColumns struct {
+	columns []ColumnExpr
+	names   map[ColumnExpr]string
+}
+
+// name 返回列在派生表中的名称，第一次引用时分配
+func (d *derivedColumns) name(col ColumnExpr) string {
+	if name, ok := d.names[col]; ok {
+		return name
+	}
+	name := fmt.Sprintf("c%d", len(d.columns)+1)
+	d.names[col] = name
+	d.columns = append(d.columns, col)
+	return name
+}
+
+// expr 把对各表列的引用改写为对派生表列的引用
+func (d *derivedColumns) expr(expr Expr) (Expr, error) {
+	switch e := expr.(type) {
+	case ColumnExpr:
+		return ColumnExpr{Qualifier: fullJoinAlias, Column: d.name(e)}, nil
+	case TemplateExpr:
+		template := expandExpression(e.Template, func(column string) string {
+			return Placeholder(d.name(ColumnExpr{Qualifier: e.Qualifier, Column: column}))
+		})
+		return TemplateExpr{Qualifier: fullJoinAlias, Template: template}, nil
+	case AggregateExpr:
+		arg, err := d.expr(e.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return AggregateExpr{Func: e.Func, Arg: arg}, nil
+	case LiteralExpr, ParamExpr, PositionExpr:
+		return e, nil
+	default:
+		return nil, fmt.Errorf("无法模拟 FULL OUTER JOIN：不能改写表达式 %v", expr)
+	}
+}
+
+// predicate 改写条件中的列引用，pred 为 nil 时返回 nil
+func (d *derivedColumns) predicate(pred Predicate) (Predicate, error) {
+	switch p := pred.(type) {
+	case nil:
+		return nil, nil
+	case Comparison:
+		left, err := d.expr(p.Left)
+		if err != nil {
+			return nil, err
+		}
+		c := Comparison{Left: left, Operator: p.Operator}
+		for _, operand := range p.Right {
+			right, err := d.expr(operand)
+			if err != nil {
+				return nil, err
+			}
+			c.Right = append(c.Right, right)
+		}
+		return c, nil
+	case Logical:
+		l := Logical{Op: p.Op}
+		for _, item := range p.Items {
+			rewritten, err := d.predicate(item)
+			if err != nil {
+				return nil, err
+			}
+			l.Items = append(l.Items, rewritten)
+		}
+		return l, nil
+	default:
+		return nil, fmt.Errorf("无法模拟 FULL OUTER JOIN：不能改写条件 %v", pred)
+	}
+}
+
+// half 派生表中的一半：FULL JOIN 按 joinType 重新应用连接策略，其余连接不变，
+// 输出外层引用的所有列
+func (d *derivedColumns) half(s *Select, full int, joinType JoinType, where Predicate) *Select {
+	sel := &Select{From: s.From, Where: where}
+	for _, col := range d.columns {
+		sel.Columns = append(sel.Columns, SelectItem{Expr: col, Alias: d.names[col]})
+	}
+	for i, join := range s.Joins {
+		t := join.Type
+		if i == full {
+			t = joinType
+		}
+		NewJoinStrategy(t).Apply(sel, join.Table, join.On)
+	}
+	return sel
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+// 带条件的 CROSS JOIN 输出为 INNER JOIN，PostgreSQL、SQL Server 和 Oracle 不允许 CROSS JOIN 后跟 ON
+func TestCrossJoinWithFilter(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		filtered string
+		cross    string
+	}{
+		{MySQL,
+			"SELECT `t1`.`x`, `t2`.`y` FROM `a` `t1` INNER JOIN `b` `t2` ON `t2`.`y` = 1;",
+			"SELECT `t1`.`x`, `t2`.`y` FROM `a` `t1` CROSS JOIN `b` `t2`;"},
+		{PostgreSQL,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" INNER JOIN "b" "t2" ON "t2"."y" = 1;`,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" CROSS JOIN "b" "t2";`},
+		{SQLite,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" INNER JOIN "b" "t2" ON "t2"."y" = 1;`,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" CROSS JOIN "b" "t2";`},
+		{SQLServer,
+			"SELECT [t1].[x], [t2].[y] FROM [a] [t1] INNER JOIN [b] [t2] ON [t2].[y] = 1;",
+			"SELECT [t1].[x], [t2].[y] FROM [a] [t1] CROSS JOIN [b] [t2];"},
+		{Oracle,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" INNER JOIN "b" "t2" ON "t2"."y" = 1;`,
+			`SELECT "t1"."x", "t2"."y" FROM "a" "t1" CROSS JOIN "b" "t2";`},
+	}
+	generate := func(d Dialect, filters []*Condition) string {
+		t.Helper()
+		g := NewSQLGenerator()
+		g.SetDialect(d)
+		g.AddTableInstance(TableInstance{ID: "a", Table: "a"})
+		g.AddTableInstance(TableInstance{ID: "b", Table: "b"})
+		g.SetMainTable("a")
+		g.AddSelectedColumn(SelectColumn{Table: "a", Name: "x"})
+		g.AddSelectedColumn(SelectColumn{Table: "b", Name: "y"})
+		g.AddJoin(JoinInfo{SourceInstance: "a", TargetInstance: "b", SourceTable: "a", TargetTable: "b",
+			JoinType: CrossJoin, Filters: filters})
+		got, err := g.GenerateSQL()
+		if err != nil {
+			t.Fatalf("%s: %v", d.Name(), err)
+		}
+		return strings.TrimSpace(got)
+	}
+	for _, tt := range tests {
+		filter := &Condition{Table: "b", Column: "y", DataType: "int", Operator: OpEqual, Values: []string{"1"}}
+		if got := generate(tt.dialect, []*Condition{filter}); got != tt.filtered {
+			t.Errorf("%s with filter:\n got %s\nwant %s", tt.dialect.Name(), got, tt.filtered)
+		}
+		if got := generate(tt.dialect, nil); got != tt.cross {
+			t.Errorf("%s without filter:\n got %s\nwant %s", tt.dialect.Name(), got, tt.cross)
+		}
+	}
+}
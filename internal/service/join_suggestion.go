@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// JoinSuggestion 推荐的连接目标及列对，SourceColumns 与 TargetColumns 按位置对应
+type JoinSuggestion struct {
+	TargetTable    string
+	SourceColumns  []string
+	TargetColumns  []string
+	FromForeignKey bool // false 表示按列名推测
+}
+
+// String 用于在界面中显示推荐项
+func (s JoinSuggestion) String() string {
+	var pairs []string
+	for i := range s.SourceColumns {
+		pairs = append(pairs, fmt.Sprintf("%s = %s", s.SourceColumns[i], s.TargetColumns[i]))
+	}
+	origin := "FK"
+	if !s.FromForeignKey {
+		origin = "guess"
+	}
+	return fmt.Sprintf("%s (%s) [%s]", s.TargetTable, strings.Join(pairs, " AND "), origin)
+}
+
+// SuggestJoins 根据外键生成连接推荐，没有外键时按 xxx_id -> xxx.id 的命名习惯推测。
+// columnsOf 用于查询候选目标表的列，只在推测时调用
+func SuggestJoins(sourceTable string, sourceColumns, tables []string, keys []model.ForeignKey,
+	columnsOf func(table string) ([]string, error)) []JoinSuggestion {
+	var suggestions []JoinSuggestion
+	seen := make(map[string]bool)
+	add := func(s JoinSuggestion) {
+		key := s.TargetTable + "|" + strings.Join(s.SourceColumns, ",") + "|" + strings.Join(s.TargetColumns, ",")
+		if !seen[key] {
+			seen[key] = true
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	// 外键：本表引用其他表，或其他表引用本表
+	for _, fk := range keys {
+		if fk.Table == sourceTable {
+			add(JoinSuggestion{TargetTable: fk.RefTable, SourceColumns: fk.Columns, TargetColumns: fk.RefColumns, FromForeignKey: true})
+		}
+		if fk.RefTable == sourceTable {
+			add(JoinSuggestion{TargetTable: fk.Table, SourceColumns: fk.RefColumns, TargetColumns: fk.Columns, FromForeignKey: true})
+		}
+	}
+	if len(suggestions) > 0 {
+		return suggestions
+	}
+
+	// 命名推测：user_id -> user.id / users.id
+	for _, column := range sourceColumns {
+		prefix, ok := foreignKeyPrefix(column)
+		if !ok {
+			continue
+		}
+		table, ok := findName(tables, prefix, prefix+"s", prefix+"es")
+		if !ok {
+			continue
+		}
+		targetColumns, err := columnsOf(table)
+		if err != nil {
+			continue
+		}
+		if id, ok := findName(targetColumns, "id"); ok {
+			add(JoinSuggestion{TargetTable: table, SourceColumns: []string{column}, TargetColumns: []string{id}})
+		}
+	}
+	return suggestions
+}
+
+// foreignKeyPrefix 返回 xxx_id 形式列名中的 xxx
+func foreignKeyPrefix(column string) (string, bool) {
+	lower := strings.ToLower(column)
+	if !strings.HasSuffix(lower, "_id") || len(lower) <= len("_id") {
+		return "", false
+	}
+	return column[:len(column)-len("_id")], true
+}
+
+// findName 按候选名称的顺序查找（不区分大小写），返回实际名称
+func findName(names []string, candidates ...string) (string, bool) {
+	for _, candidate := range candidates {
+		for _, name := range names {
+			if strings.EqualFold(name, candidate) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
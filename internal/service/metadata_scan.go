@@ -0,0 +1,197 @@
+package service
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// openDB 打开连接池并测试连接
+func openDB(driver, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %v", err)
+	}
+	return checkDB(db)
+}
+
+// openConnector 以驱动的连接器打开连接池，用于无法写进DSN的设置（例如TLS证书）
+func openConnector(connector driver.Connector) (*sql.DB, error) {
+	return checkDB(sql.OpenDB(connector))
+}
+
+func checkDB(db *sql.DB) (*sql.DB, error) {
+	// 测试连接
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("测试数据库连接失败: %v", err)
+	}
+
+	// 元数据查询都按库名限定，可以在连接池的任意连接上并发执行
+	db.SetMaxIdleConns(maxPrefetch)
+	return db, nil
+}
+
+// scanNames 读取单列的名称结果集
+func scanNames(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// scanColumn 读取一行列信息，各后端的查询按以下顺序返回：
+// 名称、类型、是否可空(YES/NO)、键、默认值、附加信息、注释、字符集。
+// prefix 为这些字段之前的其他字段
+func scanColumn(rows *sql.Rows, prefix ...any) (model.Column, error) {
+	var col model.Column
+	var nullable string
+	var default_, charset sql.NullString
+	dest := append(prefix, &col.Name, &col.Type, &nullable, &col.Key, &default_,
+		&col.Extra, &col.Comment, &charset)
+	if err := rows.Scan(dest...); err != nil {
+		return col, err
+	}
+	col.Nullable = nullable == "YES"
+	col.Default, col.HasDefault = default_.String, default_.Valid
+	col.Charset = charset.String
+	return col, nil
+}
+
+// scanColumns 读取一张表的列信息
+func scanColumns(rows *sql.Rows) ([]model.Column, error) {
+	defer rows.Close()
+	var columns []model.Column
+	for rows.Next() {
+		col, err := scanColumn(rows)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// scanSchema 读取按表排列的列信息，每行以表名和表注释开头
+func scanSchema(dbName string, rows *sql.Rows) (*model.Schema, error) {
+	defer rows.Close()
+	schema := &model.Schema{Name: dbName}
+	for rows.Next() {
+		var tableName, tableComment string
+		col, err := scanColumn(rows, &tableName, &tableComment)
+		if err != nil {
+			return nil, err
+		}
+
+		// 同一张表的列连续出现
+		if n := len(schema.Tables); n == 0 || schema.Tables[n-1].Name != tableName {
+			schema.Tables = append(schema.Tables, model.Table{Name: tableName, Comment: tableComment})
+		}
+		table := &schema.Tables[len(schema.Tables)-1]
+		table.Columns = append(table.Columns, col)
+	}
+	return schema, rows.Err()
+}
+
+// scanForeignKeys 读取外键的列映射，每行为：约束名、表、列、被引用表、被引用列
+func scanForeignKeys(rows *sql.Rows) ([]model.ForeignKey, error) {
+	defer rows.Close()
+	var keys []model.ForeignKey
+	for rows.Next() {
+		var name, table, column, refTable, refColumn string
+		if err := rows.Scan(&name, &table, &column, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		// 复合外键的各列连续出现，合并到同一个约束中
+		if n := len(keys); n > 0 && keys[n-1].Name == name && keys[n-1].Table == table {
+			keys[n-1].Columns = append(keys[n-1].Columns, column)
+			keys[n-1].RefColumns = append(keys[n-1].RefColumns, refColumn)
+			continue
+		}
+		keys = append(keys, model.ForeignKey{
+			Name:       name,
+			Table:      table,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+		})
+	}
+	return keys, rows.Err()
+}
+
+// scanIndexes 读取索引的列，每行为：索引名、表、列、是否唯一、是否主键，
+// 表达式索引的列为 NULL
+func scanIndexes(rows *sql.Rows) ([]model.Index, error) {
+	defer rows.Close()
+	var indexes []model.Index
+	for rows.Next() {
+		var name, table string
+		var column sql.NullString
+		var unique, primary bool
+		if err := rows.Scan(&name, &table, &column, &unique, &primary); err != nil {
+			return nil, err
+		}
+		// 复合索引的各列连续出现
+		if n := len(indexes); n > 0 && indexes[n-1].Name == name && indexes[n-1].Table == table {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, column.String)
+			continue
+		}
+		indexes = append(indexes, model.Index{
+			Name:    name,
+			Table:   table,
+			Columns: []string{column.String},
+			Primary: primary,
+			Unique:  unique,
+		})
+	}
+	return indexes, rows.Err()
+}
+
+// scanObjects 读取数据库对象，每行为：名称、类型、触发器所在的表
+func scanObjects(rows *sql.Rows) ([]model.SchemaObject, error) {
+	defer rows.Close()
+	var objects []model.SchemaObject
+	for rows.Next() {
+		var object model.SchemaObject
+		if err := rows.Scan(&object.Name, &object.Type, &object.Table); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+// scanDefinition 读取第一行中第 column 列的定义，没有结果或定义为 NULL（例如权限不足）时返回错误
+func scanDefinition(rows *sql.Rows, column int, name string) (string, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("找不到 %s 的定义", name)
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", err
+	}
+	if column >= len(values) || !values[column].Valid {
+		return "", fmt.Errorf("无法读取 %s 的定义，可能没有查看权限", name)
+	}
+	return values[column].String, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortDirection 排序方向
+type SortDirection string
+
+const (
+	SortNone SortDirection = ""
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+// orderItem 待排序的 ORDER BY 项及其优先级
+type orderItem struct {
+	order    OrderBy
+	priority int
+}
+
+// sortOrderItems 按优先级排序，优先级相同时保持SELECT列表中的顺序
+func sortOrderItems(items []orderItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].priority < items[j].priority
+	})
+}
+
+// limitClause 生成LIMIT/OFFSET子句，limit 为 0 表示不限制
+func limitClause(limit, offset int) (string, error) {
+	if limit < 0 || offset < 0 {
+		return "", fmt.Errorf("LIMIT 和 OFFSET 不能为负数")
+	}
+	if limit == 0 {
+		if offset > 0 {
+			return "", fmt.Errorf("设置 OFFSET 时必须同时设置 LIMIT")
+		}
+		return "", nil
+	}
+	if offset > 0 {
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset), nil
+	}
+	return fmt.Sprintf("LIMIT %d", limit), nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// columnExpr 返回列的表达式节点，计算列的占位符在渲染时展开
+func (g *SQLGenerator) columnExpr(col SelectColumn) Expr {
+	alias := g.tableAliases[col.Table]
+	if col.Expression != "" {
+		return TemplateExpr{Qualifier: alias, Template: col.Expression}
+	}
+	return ColumnExpr{Qualifier: alias, Column: col.Name}
+}
+
+// validateOutputNames 检查别名是否合法，以及不同表的输出列是否重名
+func (g *SQLGenerator) validateOutputNames() error {
+	owners := make(map[string][]string) // 小写的输出名 -> 来源列
+	var names []string
+	for _, col := range g.selectedColumns {
+		if col.Alias != "" {
+			if err := ValidateAlias(col.Alias); err != nil {
+				return err
+			}
+		}
+		// 未设置别名的聚合列由数据库生成列名，不参与检查
+		if col.Aggregate != AggNone && !col.needsAlias() {
+			continue
+		}
+		key := strings.ToLower(col.OutputName())
+		if _, exists := owners[key]; !exists {
+			names = append(names, key)
+		}
+		owners[key] = append(owners[key], fmt.Sprintf("%s.%s", col.Table, col.Name))
+	}
+
+	var duplicates []string
+	for _, name := range names {
+		if len(owners[name]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s (%s)", name, strings.Join(owners[name], ", ")))
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("输出列名重复，请设置别名: %s", strings.Join(duplicates, "; "))
+	}
+	return nil
+}
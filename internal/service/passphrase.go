@@ -0,0 +1,87 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	keyIterations = 210000 // PBKDF2-HMAC-SHA256 的迭代次数
+	keyLength     = 32     // AES-256
+	saltLength    = 16
+)
+
+// deriveKey 由主密码和盐派生加密密钥
+func deriveKey(passphrase string, salt []byte) []byte {
+	return pbkdf2(passphrase, salt, keyIterations, keyLength)
+}
+
+// pbkdf2 PBKDF2-HMAC-SHA256，见 RFC 8018。已保存的配置依赖其结果，修改后需通过 RFC 7914 的测试向量
+func pbkdf2(passphrase string, salt []byte, iterations, length int) []byte {
+	prf := hmac.New(sha256.New, []byte(passphrase))
+	var key []byte
+	for block := uint32(1); len(key) < length; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
+
+// randomBytes 生成随机字节，用于盐和随机数
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// seal 以 AES-GCM 加密，随机数放在密文之前
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open 解密 seal 的结果，密钥错误或数据被修改时返回错误
+func open(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("密文长度不正确")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
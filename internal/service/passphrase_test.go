@@ -0,0 +1,58 @@
+package service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// RFC 7914 第 11 节的 PBKDF2-HMAC-SHA256 测试向量
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		want, _ := hex.DecodeString(tt.want)
+		got := pbkdf2(tt.passphrase, []byte(tt.salt), tt.iterations, len(want))
+		if !bytes.Equal(got, want) {
+			t.Errorf("pbkdf2(%q, %q, %d) = %x, want %s", tt.passphrase, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	key := deriveKey("master", []byte("0123456789abcdef"))
+	if len(key) != keyLength {
+		t.Fatalf("deriveKey length = %d, want %d", len(key), keyLength)
+	}
+	plaintext := []byte("s3cret")
+	data, err := seal(key, plaintext)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	got, err := open(key, data)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("open = %q, want %q", got, plaintext)
+	}
+
+	// 主密码错误时无法解密
+	wrong := deriveKey("other", []byte("0123456789abcdef"))
+	if _, err := open(wrong, data); err == nil {
+		t.Error("open with a wrong key succeeded")
+	}
+	if strings.Contains(string(data), string(plaintext)) {
+		t.Error("sealed data contains the plaintext")
+	}
+}
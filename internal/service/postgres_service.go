@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+	"github.com/lowSqlGen/internal/model"
+)
+
+// postgresService PostgreSQL 数据库服务，元数据读取自 pg_catalog。
+// 界面中的“数据库”对应连接数据库中的模式（schema）
+type postgresService struct {
+	db     *sql.DB
+	config *model.DatabaseConfig
+}
+
+// pgColumnFields 列信息字段，顺序与 scanColumn 一致，需要 c(pg_class) 和 a(pg_attribute) 以及 d(pg_attrdef)
+const pgColumnFields = `
+	a.attname,
+	pg_catalog.format_type(a.atttypid, a.atttypmod),
+	CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END,
+	CASE
+		WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_index i
+			WHERE i.indrelid = c.oid AND i.indisprimary AND a.attnum = ANY(i.indkey)) THEN 'PRI'
+		WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_index i
+			WHERE i.indrelid = c.oid AND i.indisunique AND i.indnatts = 1 AND i.indkey[0] = a.attnum) THEN 'UNI'
+		WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_index i
+			WHERE i.indrelid = c.oid AND i.indkey[0] = a.attnum) THEN 'MUL'
+		ELSE ''
+	END,
+	pg_catalog.pg_get_expr(d.adbin, d.adrelid),
+	CASE
+		WHEN a.attidentity <> '' THEN 'identity'
+		WHEN pg_catalog.pg_get_expr(d.adbin, d.adrelid) LIKE 'nextval(%' THEN 'serial'
+		ELSE ''
+	END,
+	COALESCE(pg_catalog.col_description(c.oid, a.attnum), ''),
+	NULL::text`
+
+// pgColumnSource 表及其列的来源，过滤掉已删除的列和系统列
+const pgColumnSource = `
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+	LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = c.oid AND d.adnum = a.attnum`
+
+// pgRelationKinds 可以出现在查询中的关系：普通表、分区表、视图、物化视图和外部表
+const pgRelationKinds = `c.relkind IN ('r', 'p', 'v', 'm', 'f')`
+
+func newPostgresService(config *model.DatabaseConfig) (DatabaseService, error) {
+	db, err := openDB("postgres", postgresDSN(config))
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresService{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// GetDatabases 获取当前数据库中的用户模式
+func (s *postgresService) GetDatabases() ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT nspname
+		FROM pg_catalog.pg_namespace
+		WHERE nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema'
+		ORDER BY nspname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+func (s *postgresService) GetTables(schema string) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT c.relname
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND `+pgRelationKinds+`
+		ORDER BY c.relname
+	`, schema)
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+// GetColumns 按定义顺序获取列及其类型、约束和注释
+func (s *postgresService) GetColumns(schema, tableName string) ([]model.Column, error) {
+	rows, err := s.db.Query(`SELECT `+pgColumnFields+pgColumnSource+`
+		WHERE n.nspname = $1 AND c.relname = $2
+		ORDER BY a.attnum
+	`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanColumns(rows)
+}
+
+func (s *postgresService) Close() error {
+	return s.db.Close()
+}
+
+// GetTableComment 获取表注释
+func (s *postgresService) GetTableComment(schema, tableName string) string {
+	var comment string
+	err := s.db.QueryRow(`
+		SELECT COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), '')
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+	`, schema, tableName).Scan(&comment)
+	if err != nil {
+		return ""
+	}
+	return comment
+}
+
+// GetForeignKeys 获取引用该表或被该表引用的外键
+func (s *postgresService) GetForeignKeys(schema, tableName string) ([]model.ForeignKey, error) {
+	rows, err := s.db.Query(`
+		SELECT con.conname, cl.relname, att.attname, rcl.relname, ratt.attname
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class cl ON cl.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = cl.relnamespace
+		JOIN pg_catalog.pg_class rcl ON rcl.oid = con.confrelid
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+		JOIN pg_catalog.pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+		JOIN pg_catalog.pg_attribute ratt ON ratt.attrelid = con.confrelid AND ratt.attnum = k.refattnum
+		WHERE con.contype = 'f' AND n.nspname = $1 AND (cl.relname = $2 OR rcl.relname = $2)
+		ORDER BY cl.relname, con.conname, k.ord
+	`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(rows)
+}
+
+// GetIndexes 获取表的索引，不包括 INCLUDE 的附加列
+func (s *postgresService) GetIndexes(schema, tableName string) ([]model.Index, error) {
+	rows, err := s.db.Query(`
+		SELECT ic.relname, c.relname, a.attname, i.indisunique, i.indisprimary
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class c ON c.oid = i.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1 AND c.relname = $2 AND k.ord <= i.indnkeyatts
+		ORDER BY ic.relname, k.ord
+	`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanIndexes(rows)
+}
+
+// GetObjects 获取模式中的关系、函数、存储过程和触发器，重载的函数只列出一次
+func (s *postgresService) GetObjects(schema string) ([]model.SchemaObject, error) {
+	rows, err := s.db.Query(`
+		SELECT c.relname::text, CASE WHEN c.relkind IN ('v', 'm') THEN 'view' ELSE 'table' END, ''
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND `+pgRelationKinds+`
+		UNION
+		SELECT p.proname::text, CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END, ''
+		FROM pg_catalog.pg_proc p
+		JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = $1 AND p.prokind IN ('f', 'p')
+		UNION
+		SELECT t.tgname::text, 'trigger', c.relname::text
+		FROM pg_catalog.pg_trigger t
+		JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND NOT t.tgisinternal
+		ORDER BY 2, 1
+	`, schema)
+	if err != nil {
+		return nil, err
+	}
+	return scanObjects(rows)
+}
+
+// GetDefinition 获取视图、函数、存储过程或触发器的定义，重载的函数依次列出所有版本。
+// PostgreSQL 没有获取建表语句的函数，不支持表
+func (s *postgresService) GetDefinition(schema string, object model.SchemaObject) (string, error) {
+	var query string
+	args := []any{schema, object.Name}
+	switch object.Type {
+	case model.ObjectView:
+		query = `
+			SELECT CASE c.relkind WHEN 'm' THEN 'CREATE MATERIALIZED VIEW ' ELSE 'CREATE VIEW ' END
+				|| quote_ident(c.relname) || E' AS\n' || pg_catalog.pg_get_viewdef(c.oid, true)
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relname = $2`
+	case model.ObjectProcedure, model.ObjectFunction:
+		query = `
+			SELECT string_agg(pg_catalog.pg_get_functiondef(p.oid), E'\n\n' ORDER BY p.oid)
+			FROM pg_catalog.pg_proc p
+			JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2 AND p.prokind IN ('f', 'p')`
+	case model.ObjectTrigger:
+		query = `
+			SELECT pg_catalog.pg_get_triggerdef(t.oid, true)
+			FROM pg_catalog.pg_trigger t
+			JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND t.tgname = $2 AND c.relname = $3`
+		args = append(args, object.Table)
+	default:
+		return "", fmt.Errorf("PostgreSQL 不支持查看%s的定义", object.Type)
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return "", err
+	}
+	return scanDefinition(rows, 0, object.Name)
+}
+
+// LoadSchema 一次查询加载模式中所有表的注释和列信息
+func (s *postgresService) LoadSchema(schema string) (*model.Schema, error) {
+	rows, err := s.db.Query(`
+		SELECT c.relname, COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), ''),`+pgColumnFields+pgColumnSource+`
+		WHERE n.nspname = $1 AND `+pgRelationKinds+`
+		ORDER BY c.relname, a.attnum
+	`, schema)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchema(schema, rows)
+}
+
+// Query 在单独的连接上把 search_path 设为 schema 后执行查询。search_path 对整个会话有效，
+// 连接归还连接池后仍然保留，因此 schema 为空时恢复默认值，不沿用上一次查询的设置
+func (s *postgresService) Query(ctx context.Context, schema, query string) (*RowIterator, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schema != "" {
+		_, err = conn.ExecContext(ctx, `SELECT pg_catalog.set_config('search_path', $1, false)`, PostgreSQL.QuoteIdentifier(schema))
+	} else {
+		_, err = conn.ExecContext(ctx, `RESET search_path`)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return queryRows(ctx, conn, query)
+}
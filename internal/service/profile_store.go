@@ -0,0 +1,215 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// passphraseCheck 用主密码加密后保存，解密成功即说明主密码正确
+var passphraseCheck = []byte("lowSqlGen")
+
+// ConnectionProfile 保存的连接配置，密码以主密码加密后保存
+type ConnectionProfile struct {
+	Name string `json:"name"`
+	model.DatabaseConfig
+	EncryptedPassword []byte `json:"password,omitempty"`
+}
+
+// profileFile 连接配置文件的内容
+type profileFile struct {
+	Salt     []byte              `json:"salt,omitempty"`  // 派生密钥的盐，设置主密码前为空
+	Check    []byte              `json:"check,omitempty"` // 加密后的 passphraseCheck
+	Profiles []ConnectionProfile `json:"profiles"`
+}
+
+// ProfileStore 连接配置的存储，读取密码前需要用主密码解锁
+type ProfileStore struct {
+	path string
+	file profileFile
+	key  []byte
+}
+
+// DefaultProfilePath 用户配置目录下的连接配置文件
+func DefaultProfilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lowSqlGen", "profiles.json"), nil
+}
+
+// LoadProfileStore 读取连接配置文件，文件不存在时返回空的存储
+func LoadProfileStore(path string) (*ProfileStore, error) {
+	store := &ProfileStore{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.file); err != nil {
+		return nil, fmt.Errorf("连接配置文件格式错误: %v", err)
+	}
+	return store, nil
+}
+
+// Names 返回所有连接配置的名称，按名称排序
+func (s *ProfileStore) Names() []string {
+	names := make([]string, 0, len(s.file.Profiles))
+	for _, profile := range s.file.Profiles {
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasProfile 是否存在名为 name 的连接配置
+func (s *ProfileStore) HasProfile(name string) bool {
+	return s.find(name) != nil
+}
+
+// HasPassphrase 是否已经设置过主密码
+func (s *ProfileStore) HasPassphrase() bool {
+	return len(s.file.Salt) > 0
+}
+
+// Unlocked 是否已经输入主密码
+func (s *ProfileStore) Unlocked() bool {
+	return s.key != nil
+}
+
+// NeedsUnlock 读取配置 name 的密码前是否需要输入主密码
+func (s *ProfileStore) NeedsUnlock(name string) bool {
+	profile := s.find(name)
+	return profile != nil && len(profile.EncryptedPassword) > 0 && !s.Unlocked()
+}
+
+// Unlock 校验主密码并解锁，第一次使用时设置主密码
+func (s *ProfileStore) Unlock(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("主密码不能为空")
+	}
+	if !s.HasPassphrase() {
+		salt, err := randomBytes(saltLength)
+		if err != nil {
+			return err
+		}
+		key := deriveKey(passphrase, salt)
+		check, err := seal(key, passphraseCheck)
+		if err != nil {
+			return err
+		}
+		s.file.Salt, s.file.Check = salt, check
+		if err := s.write(); err != nil {
+			s.file.Salt, s.file.Check = nil, nil
+			return err
+		}
+		s.key = key
+		return nil
+	}
+
+	key := deriveKey(passphrase, s.file.Salt)
+	if _, err := open(key, s.file.Check); err != nil {
+		return fmt.Errorf("主密码错误")
+	}
+	s.key = key
+	return nil
+}
+
+// Config 返回配置 name 的连接配置，包括解密后的密码
+func (s *ProfileStore) Config(name string) (*model.DatabaseConfig, error) {
+	profile := s.find(name)
+	if profile == nil {
+		return nil, fmt.Errorf("连接配置 %s 不存在", name)
+	}
+	config := profile.DatabaseConfig
+	config.Profile = name
+	if len(profile.EncryptedPassword) > 0 {
+		if !s.Unlocked() {
+			return nil, fmt.Errorf("请先输入主密码")
+		}
+		password, err := open(s.key, profile.EncryptedPassword)
+		if err != nil {
+			return nil, fmt.Errorf("连接配置 %s 的密码无法解密: %v", name, err)
+		}
+		config.Password = string(password)
+	}
+	return &config, nil
+}
+
+// Save 以 name 保存连接配置，同名配置会被覆盖。保存密码前需要先解锁
+func (s *ProfileStore) Save(name string, config *model.DatabaseConfig) error {
+	if name == "" {
+		return fmt.Errorf("连接配置名称不能为空")
+	}
+	profile := ConnectionProfile{Name: name, DatabaseConfig: *config}
+	profile.Profile, profile.Password, profile.CurrentDB = "", "", ""
+	if config.Password != "" {
+		if !s.Unlocked() {
+			return fmt.Errorf("请先输入主密码")
+		}
+		encrypted, err := seal(s.key, []byte(config.Password))
+		if err != nil {
+			return err
+		}
+		profile.EncryptedPassword = encrypted
+	}
+
+	previous := s.file.Profiles
+	s.file.Profiles = append([]ConnectionProfile{profile}, s.without(name)...)
+	if err := s.write(); err != nil {
+		s.file.Profiles = previous
+		return err
+	}
+	config.Profile = name
+	return nil
+}
+
+// Delete 删除连接配置 name
+func (s *ProfileStore) Delete(name string) error {
+	previous := s.file.Profiles
+	s.file.Profiles = s.without(name)
+	if err := s.write(); err != nil {
+		s.file.Profiles = previous
+		return err
+	}
+	return nil
+}
+
+func (s *ProfileStore) find(name string) *ConnectionProfile {
+	for i := range s.file.Profiles {
+		if s.file.Profiles[i].Name == name {
+			return &s.file.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// without 返回除 name 以外的配置
+func (s *ProfileStore) without(name string) []ConnectionProfile {
+	var profiles []ConnectionProfile
+	for _, profile := range s.file.Profiles {
+		if profile.Name != name {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// write 写入配置文件，目录只允许当前用户访问
+func (s *ProfileStore) write() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, append(data, '\n'))
+}
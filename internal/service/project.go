@@ -0,0 +1,107 @@
+package service
+
+import (
+	"github.com/lowSqlGen/internal/model"
+)
+
+// ProjectVersion 当前项目文件格式的版本，格式变化时递增并在 projectMigrations 中追加升级函数
+const ProjectVersion = 1
+
+// Project 保存到文件的查询设计，表实例、列和连接都通过实例ID引用
+type Project struct {
+	Version    int              `json:"version"`
+	Connection ConnectionRef    `json:"connection"`
+	Database   string           `json:"database"`
+	RootTable  string           `json:"rootTable,omitempty"`
+	Tables     []ProjectTable   `json:"tables"`
+	Joins      []ProjectJoin    `json:"joins,omitempty"`
+	Output     []ColumnRef      `json:"output,omitempty"` // 输出列的顺序
+	Options    GeneratorOptions `json:"options"`
+}
+
+// ConnectionRef 项目使用的数据库连接，不保存密码。Profile 为保存的连接配置名称，
+// 打开项目时优先使用该配置，其余字段在配置不存在时用于手动连接
+type ConnectionRef struct {
+	Profile  string `json:"profile,omitempty"`
+	Driver   string `json:"driver,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Port     string `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Database string `json:"database,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+}
+
+// NewConnectionRef 从连接配置中取出用于识别连接的部分
+func NewConnectionRef(config *model.DatabaseConfig) ConnectionRef {
+	return ConnectionRef{
+		Profile:  config.Profile,
+		Driver:   config.Driver,
+		Host:     config.Host,
+		Port:     config.Port,
+		Username: config.Username,
+		Database: config.Database,
+		FilePath: config.FilePath,
+	}
+}
+
+// Matches 判断连接配置是否指向同一个数据库，不比较配置名称
+func (r ConnectionRef) Matches(config *model.DatabaseConfig) bool {
+	if config == nil {
+		return false
+	}
+	current := NewConnectionRef(config)
+	current.Profile = r.Profile
+	return r == current
+}
+
+// Config 返回连接配置，密码需要另外填写
+func (r ConnectionRef) Config() *model.DatabaseConfig {
+	return &model.DatabaseConfig{
+		Profile:  r.Profile,
+		Driver:   r.Driver,
+		Host:     r.Host,
+		Port:     r.Port,
+		Username: r.Username,
+		Database: r.Database,
+		FilePath: r.FilePath,
+	}
+}
+
+// ProjectTable 画布上的一个表实例
+type ProjectTable struct {
+	TableInstance
+	X           float32         `json:"x"`
+	Y           float32         `json:"y"`
+	ShowColumns bool            `json:"showColumns"`
+	Columns     []ProjectColumn `json:"columns,omitempty"` // 勾选或修改过设置的列，以及所有计算列
+}
+
+// ProjectColumn 列的勾选状态和输出设置
+type ProjectColumn struct {
+	Name         string        `json:"name"`
+	Expression   string        `json:"expression,omitempty"`
+	Checked      bool          `json:"checked,omitempty"`
+	Alias        string        `json:"alias,omitempty"`
+	Aggregate    AggregateFunc `json:"aggregate,omitempty"`
+	Sort         SortDirection `json:"sort,omitempty"`
+	SortPriority int           `json:"sortPriority,omitempty"`
+}
+
+// ProjectJoin 两个表实例之间的连线
+type ProjectJoin struct {
+	Source     string          `json:"source"`
+	Target     string          `json:"target"`
+	JoinType   JoinType        `json:"joinType"`
+	Conditions []JoinCondition `json:"conditions,omitempty"`
+	Filters    []*Condition    `json:"filters,omitempty"`
+}
+
+// GeneratorOptions 画布之外的生成选项
+type GeneratorOptions struct {
+	Dialect  string       `json:"dialect,omitempty"`
+	Distinct bool         `json:"distinct,omitempty"`
+	Limit    int          `json:"limit,omitempty"`
+	Offset   int          `json:"offset,omitempty"`
+	Where    *FilterGroup `json:"where,omitempty"`
+	Having   *FilterGroup `json:"having,omitempty"`
+}
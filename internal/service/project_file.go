@@ -0,0 +1,84 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// projectMigration 把项目文件从某个版本升级到下一版本，直接修改解码后的 JSON 对象
+type projectMigration func(doc map[string]any) error
+
+// projectMigrations 第 i 项把版本 i+1 升级到版本 i+2
+var projectMigrations = []projectMigration{}
+
+// LoadProject 读取项目文件，旧版本的文件依次升级到当前版本
+func LoadProject(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("项目文件格式错误: %v", err)
+	}
+
+	version, ok := doc["version"].(float64)
+	if !ok || version < 1 || version != float64(int(version)) {
+		return nil, fmt.Errorf("%s 不是项目文件", filepath.Base(path))
+	}
+	if int(version) > ProjectVersion {
+		return nil, fmt.Errorf("项目文件版本 %d 高于当前支持的版本 %d，请升级程序", int(version), ProjectVersion)
+	}
+	if err := migrateProject(doc, int(version)); err != nil {
+		return nil, err
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	var project Project
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("项目文件格式错误: %v", err)
+	}
+	return &project, nil
+}
+
+// migrateProject 从 version 开始逐版本升级
+func migrateProject(doc map[string]any, version int) error {
+	for v := version; v < ProjectVersion; v++ {
+		if err := projectMigrations[v-1](doc); err != nil {
+			return fmt.Errorf("项目文件从版本 %d 升级失败: %v", v, err)
+		}
+		doc["version"] = v + 1
+	}
+	return nil
+}
+
+// SaveProject 以当前版本写入项目文件，先写临时文件再替换，避免写入中断损坏原文件
+func SaveProject(path string, project *Project) error {
+	project.Version = ProjectVersion
+	data, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic 先写同目录下的临时文件再替换目标文件，文件权限为 0600
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadProject(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "query.json")
+	project := &Project{
+		Connection: ConnectionRef{Profile: "local", Driver: "mysql", Host: "localhost", Database: "hr"},
+		Database:   "hr",
+		RootTable:  "emp#3",
+		Tables: []ProjectTable{
+			{TableInstance: TableInstance{ID: "emp#3", Table: "emp", Alias: "e"}, X: 10, Y: 20, ShowColumns: true,
+				Columns: []ProjectColumn{{Name: "name", Checked: true, Sort: SortAsc, SortPriority: 1}}},
+			{TableInstance: TableInstance{ID: "emp", Table: "emp", Database: "archive"}, X: 300, Y: 20},
+		},
+		Joins: []ProjectJoin{{
+			Source:     "emp#3",
+			Target:     "emp",
+			JoinType:   LeftJoin,
+			Conditions: []JoinCondition{{Left: ColumnRef{"emp#3", "mgr"}, Operator: OpEqual, Right: ColumnRef{"emp", "id"}}},
+		}},
+		Output:  []ColumnRef{{"emp#3", "name"}},
+		Options: GeneratorOptions{Dialect: "MySQL", Distinct: true, Limit: 10},
+	}
+	if err := SaveProject(path, project); err != nil {
+		t.Fatalf("SaveProject: %v", err)
+	}
+	// 覆盖已有文件，不留下临时文件
+	if err := SaveProject(path, project); err != nil {
+		t.Fatalf("SaveProject over an existing file: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries after saving, want 1", len(entries))
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("project file mode = %v, want 0600", perm)
+	}
+
+	loaded, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if project.Version != ProjectVersion {
+		t.Errorf("saved version = %d, want %d", project.Version, ProjectVersion)
+	}
+	if !reflect.DeepEqual(loaded, project) {
+		t.Errorf("round trip changed the project:\n got %+v\nwant %+v", loaded, project)
+	}
+}
+
+// 版本号不是正整数或高于当前版本的文件不能打开
+func TestLoadProjectVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing", `{"database": "hr", "tables": []}`},
+		{"zero", `{"version": 0, "tables": []}`},
+		{"fraction", `{"version": 1.5, "tables": []}`},
+		{"string", `{"version": "1", "tables": []}`},
+		{"newer", fmt.Sprintf(`{"version": %d, "tables": []}`, ProjectVersion+1)},
+		{"not json", `version 1`},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name+".json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadProject(path); err == nil {
+			t.Errorf("%s: LoadProject accepted %s", tt.name, tt.content)
+		}
+	}
+
+	path := filepath.Join(dir, "current.json")
+	content := fmt.Sprintf(`{"version": %d, "database": "hr", "tables": [{"id": "t#2", "table": "t"}]}`, ProjectVersion)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	project, err := LoadProject(path)
+	if err != nil {
+		t.Fatalf("LoadProject: %v", err)
+	}
+	if len(project.Tables) != 1 || project.Tables[0].ID != "t#2" {
+		t.Errorf("LoadProject tables = %+v", project.Tables)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+// 查询语法树：生成器根据画布内容构建语法树，再由方言渲染器输出SQL
+
+// Query 可渲染的查询语句，*Select 或 *Union
+type Query interface {
+	queryNode()
+}
+
+// Select 单条SELECT语句
+type Select struct {
+	Distinct bool
+	Columns  []SelectItem
+	From     TableRef
+	Joins    []Join
+	Where    Predicate // 为空表示没有WHERE子句
+	GroupBy  []Expr
+	Having   Predicate
+	OrderBy  []OrderBy
+	Limit    int // 0 表示不限制
+	Offset   int
+}
+
+// Union 以 UNION 合并的多条SELECT，排序和分页作用于合并后的结果
+type Union struct {
+	All     bool // UNION ALL，保留重复行
+	Selects []*Select
+	OrderBy []OrderBy
+	Limit   int
+	Offset  int
+}
+
+func (*Select) queryNode() {}
+func (*Union) queryNode()  {}
+
+// SelectItem SELECT 列表中的一项，Alias 为空时不输出 AS
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+}
+
+// TableRef FROM/JOIN 中的表及其别名
+type TableRef struct {
+	Table   string
+	Alias   string
+	Derived *Union // 派生表，非空时 Table 为空
+}
+
+// Join JOIN 子句，On 为空时不输出ON条件
+type Join struct {
+	Type  JoinType
+	Table TableRef
+	On    Predicate
+}
+
+// OrderBy ORDER BY 中的一项
+type OrderBy struct {
+	Expr      Expr
+	Direction SortDirection
+}
+
+// Expr 表达式节点
+type Expr interface {
+	exprNode()
+}
+
+// ColumnExpr 以表别名限定的列引用
+type ColumnExpr struct {
+	Qualifier string
+	Column    string
+}
+
+// TemplateExpr 计算列表达式，其中的 {列名} 占位符引用 Qualifier 所指表的列
+type TemplateExpr struct {
+	Qualifier string
+	Template  string
+}
+
+// AggregateExpr 作用于表达式的聚合函数
+type AggregateExpr struct {
+	Func AggregateFunc
+	Arg  Expr
+}
+
+// LiteralExpr 界面输入的比较值，按数据类型决定是否加引号
+type LiteralExpr struct {
+	Value    string
+	DataType string
+}
+
+// ParamExpr 参数占位符
+type ParamExpr struct{}
+
+// PositionExpr SELECT 列表中的序号（从1开始），用于 UNION 的排序
+type PositionExpr struct {
+	Position int
+}
+
+// RawExpr 原样输出的SQL片段，用于界面描述
+type RawExpr struct {
+	SQL string
+}
+
+func (ColumnExpr) exprNode()    {}
+func (TemplateExpr) exprNode()  {}
+func (AggregateExpr) exprNode() {}
+func (LiteralExpr) exprNode()   {}
+func (ParamExpr) exprNode()     {}
+func (PositionExpr) exprNode()  {}
+func (RawExpr) exprNode()       {}
+
+// Predicate 条件节点
+type Predicate interface {
+	predicateNode()
+}
+
+// Comparison 以 Operator 比较左侧表达式与右侧的操作数，
+// IS NULL 没有操作数，BETWEEN 有两个，IN 可以有多个
+type Comparison struct {
+	Left     Expr
+	Operator FilterOperator
+	Right    []Expr
+}
+
+// Logical 以 AND/OR 组合的条件，嵌套的组合条件输出时加括号
+type Logical struct {
+	Op    LogicOperator
+	Items []Predicate
+}
+
+func (Comparison) predicateNode() {}
+func (Logical) predicateNode()    {}
+
+// aggregateExpr 把聚合函数作用于表达式，AggNone 时原样返回
+func aggregateExpr(a AggregateFunc, arg Expr) Expr {
+	if a == AggNone {
+		return arg
+	}
+	return AggregateExpr{Func: a, Arg: arg}
+}
+
+// and 以 AND 组合条件，没有条件时返回 nil
+func and(items []Predicate) Predicate {
+	if len(items) == 0 {
+		return nil
+	}
+	return Logical{Op: LogicAnd, Items: items}
+}
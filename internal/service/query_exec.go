@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// QueryResult 执行查询的结果，行数超过上限时只保留前面的行
+type QueryResult struct {
+	Columns   []ResultColumn
+	Rows      [][]any // NULL 为 nil，文本为 string，二进制为 []byte
+	Truncated bool    // 结果超过行数上限，之后的行未读取
+	Elapsed   time.Duration
+}
+
+// ResultColumn 结果列的名称和数据库类型
+type ResultColumn struct {
+	Name string
+	Type string // 驱动报告的类型名，例如 VARCHAR、INT4，未知时为空
+}
+
+// writeKeywords 出现在语句中即可能修改数据的关键字
+var writeKeywords = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "INTO", "CREATE", "DROP", "ALTER", "TRUNCATE"}
+
+// CheckQuery 只允许执行单条 SELECT 语句，避免在预览中改过的语句误修改数据。
+// 只识别字符串、注释、引号标识符和分号，画布不支持的运算符不影响执行
+func CheckQuery(sql string, d Dialect) error {
+	tokens, err := tokenize(sql, d, true)
+	if err != nil {
+		return err
+	}
+	i := 0
+	for tokens[i].is("(") {
+		i++
+	}
+	if !tokens[i].is("SELECT") && !tokens[i].is("WITH") {
+		return fmt.Errorf("只能执行 SELECT 查询")
+	}
+	for j, t := range tokens {
+		for _, keyword := range writeKeywords {
+			if t.is(keyword) {
+				return fmt.Errorf("只能执行 SELECT 查询，语句中包含 %s", keyword)
+			}
+		}
+		if t.is(";") && tokens[j+1].kind != tokEOF && !tokens[j+1].is(";") {
+			return fmt.Errorf("只能执行一条语句")
+		}
+	}
+	return nil
+}
+
+// Execute 在数据库 dbName 中执行查询并读取最多 maxRows 行，maxRows 为 0 表示不限制
+func Execute(ctx context.Context, svc DatabaseService, dbName, query string, maxRows int) (*QueryResult, error) {
+	start := time.Now()
+	it, err := svc.Query(ctx, dbName, query)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	result := &QueryResult{Columns: it.Columns()}
+	for it.Next() {
+		if maxRows > 0 && len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		result.Rows = append(result.Rows, it.Row())
+	}
+	if !result.Truncated {
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// FormatValue 把结果中的值格式化为显示用的文本，NULL 显示为 NULL
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case time.Time:
+		if v.Nanosecond() != 0 {
+			return v.Format("2006-01-02 15:04:05.999999")
+		}
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
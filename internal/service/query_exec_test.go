@@ -0,0 +1,36 @@
+package service
+
+import "testing"
+
+func TestCheckQuery(t *testing.T) {
+	tests := []struct {
+		sql     string
+		dialect Dialect
+		ok      bool
+	}{
+		{"SELECT a & b FROM t", MySQL, true},
+		{"SELECT a FROM t WHERE x ~ 'a'", PostgreSQL, true},
+		{"SELECT a FROM t WHERE tags @> ARRAY['x'] AND flags # 1 = 0", PostgreSQL, true},
+		{"SELECT a FROM t # 注释 DELETE\nWHERE b = 1", MySQL, true},
+		{"SELECT a FROM t -- DROP TABLE t\n;", MySQL, true},
+		{"SELECT 'DELETE; DROP' AS `UPDATE` FROM t;", MySQL, true},
+		{"(SELECT a FROM t) UNION (SELECT a FROM u)", SQLite, true},
+		{"WITH x AS (SELECT 1 AS n) SELECT n FROM x", PostgreSQL, true},
+		{"SELECT a FROM t;;", SQLServer, true},
+		{"SELECT a € b FROM t", Oracle, true},
+
+		{"DELETE FROM t", MySQL, false},
+		{"SELECT a FROM t; DELETE FROM t", MySQL, false},
+		{"SELECT a INTO u FROM t", PostgreSQL, false},
+		{"WITH x AS (DELETE FROM t RETURNING a) SELECT a FROM x", PostgreSQL, false},
+		{"SELECT a FROM t # x\n; DROP TABLE t", MySQL, false},
+		{"SHOW TABLES", MySQL, false},
+		{"SELECT 'a FROM t", MySQL, false},
+	}
+	for _, tt := range tests {
+		err := CheckQuery(tt.sql, tt.dialect)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckQuery(%q, %s) = %v, want ok=%v", tt.sql, tt.dialect.Name(), err, tt.ok)
+		}
+	}
+}
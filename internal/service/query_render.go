@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Renderer 按方言把查询语法树输出为SQL
+type Renderer struct {
+	dialect Dialect
+	params  int // 已输出的参数个数，参数按输出顺序编号
+}
+
+func NewRenderer(dialect Dialect) *Renderer {
+	return &Renderer{dialect: dialect}
+}
+
+// Render 输出完整的SQL语句；方言不支持 FULL OUTER JOIN 时先把查询改写为 UNION
+func (r *Renderer) Render(query Query) (string, error) {
+	if sel, ok := query.(*Select); ok && sel.hasJoin(FullJoin) && !r.dialect.SupportsFullJoin() {
+		emulated, err := emulateFullJoin(sel)
+		if err != nil {
+			return "", err
+		}
+		query = emulated
+	}
+	r.params = 0
+
+	var sql string
+	var err error
+	switch q := query.(type) {
+	case *Select:
+		sql, err = r.renderSelect(q)
+	case *Union:
+		sql, err = r.renderUnion(q)
+	default:
+		err = fmt.Errorf("不支持的查询类型 %T", query)
+	}
+	if err != nil {
+		return "", err
+	}
+	return sql + ";", nil
+}
+
+// renderSelect 输出单条SELECT语句，包括排序和分页
+func (r *Renderer) renderSelect(s *Select) (string, error) {
+	top, suffix, err := r.dialect.Limit(s.Limit, s.Offset, len(s.OrderBy) > 0)
+	if err != nil {
+		return "", err
+	}
+	return r.selectBody(s, top) + r.tail(s.OrderBy, suffix), nil
+}
+
+// renderUnion 输出以 UNION 合并的语句，排序和分页位于最后
+func (r *Renderer) renderUnion(u *Union) (string, error) {
+	top, suffix, err := r.dialect.Limit(u.Limit, u.Offset, len(u.OrderBy) > 0)
+	if err != nil {
+		return "", err
+	}
+	if top != "" {
+		return "", fmt.Errorf("%s 不支持对 UNION 的结果分页", r.dialect.Name())
+	}
+	return r.unionBody(u) + r.tail(u.OrderBy, suffix), nil
+}
+
+// unionBody 输出以 UNION 或 UNION ALL 连接的各条SELECT
+func (r *Renderer) unionBody(u *Union) string {
+	parts := make([]string, len(u.Selects))
+	for i, sel := range u.Selects {
+		parts[i] = r.selectBody(sel, "")
+	}
+	op := " UNION "
+	if u.All {
+		op = " UNION ALL "
+	}
+	return strings.Join(parts, op)
+}
+
+// selectBody 输出 SELECT 到 HAVING 的部分，top 为紧跟 SELECT 的分页写法
+func (r *Renderer) selectBody(s *Select, top string) string {
+	var b strings.Builder
+	b.WriteString("SELECT")
+	if s.Distinct {
+		b.WriteString(" DISTINCT")
+	}
+	if top != "" {
+		b.WriteString(" " + top)
+	}
+
+	items := make([]string, len(s.Columns))
+	for i, item := range s.Columns {
+		items[i] = r.Expr(item.Expr)
+		if item.Alias != "" {
+			items[i] += " AS " + r.dialect.QuoteIdentifier(item.Alias)
+		}
+	}
+	b.WriteString(" " + strings.Join(items, ", "))
+	b.WriteString(" FROM " + r.tableRef(s.From))
+
+	for _, join := range s.Joins {
+		b.WriteString(" " + string(join.Type) + " " + r.tableRef(join.Table))
+		if join.On != nil {
+			b.WriteString(" ON " + r.Predicate(join.On))
+		}
+	}
+	if s.Where != nil {
+		b.WriteString(" WHERE " + r.Predicate(s.Where))
+	}
+	if len(s.GroupBy) > 0 {
+		b.WriteString(" GROUP BY " + r.exprList(s.GroupBy))
+	}
+	if s.Having != nil {
+		b.WriteString(" HAVING " + r.Predicate(s.Having))
+	}
+	return b.String()
+}
+
+// tail 输出ORDER BY和分页子句
+func (r *Renderer) tail(orderBy []OrderBy, limit string) string {
+	var tail string
+	if len(orderBy) > 0 {
+		parts := make([]string, len(orderBy))
+		for i, item := range orderBy {
+			parts[i] = fmt.Sprintf("%s %s", r.Expr(item.Expr), item.Direction)
+		}
+		tail += " ORDER BY " + strings.Join(parts, ", ")
+	}
+	if limit != "" {
+		tail += " " + limit
+	}
+	return tail
+}
+
+// tableRef 输出表或派生表及其别名，别名与表名一样加引号，因此可以使用关键字
+func (r *Renderer) tableRef(ref TableRef) string {
+	if ref.Derived != nil {
+		return "(" + r.unionBody(ref.Derived) + ") " + r.dialect.QuoteIdentifier(ref.Alias)
+	}
+	if ref.Alias == "" {
+		return r.dialect.QuoteIdentifier(ref.Table)
+	}
+	return r.dialect.QuoteIdentifier(ref.Table) + " " + r.dialect.QuoteIdentifier(ref.Alias)
+}
+
+func (r *Renderer) exprList(exprs []Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = r.Expr(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Expr 输出表达式
+func (r *Renderer) Expr(expr Expr) string {
+	switch e := expr.(type) {
+	case ColumnExpr:
+		return r.column(e.Qualifier, e.Column)
+	case TemplateExpr:
+		expanded := expandExpression(e.Template, func(column string) string {
+			return r.column(e.Qualifier, column)
+		})
+		return translateFunctions(expanded, r.dialect)
+	case AggregateExpr:
+		return applyAggregate(r.dialect, e.Func, r.Expr(e.Arg))
+	case LiteralExpr:
+		return literal(r.dialect, e.Value, e.DataType)
+	case ParamExpr:
+		r.params++
+		return r.dialect.Placeholder(r.params)
+	case PositionExpr:
+		return fmt.Sprintf("%d", e.Position)
+	case RawExpr:
+		return e.SQL
+	default:
+		return ""
+	}
+}
+
+func (r *Renderer) column(qualifier, column string) string {
+	if qualifier == "" {
+		return r.dialect.QuoteIdentifier(column)
+	}
+	return r.dialect.QuoteIdentifier(qualifier) + "." + r.dialect.QuoteIdentifier(column)
+}
+
+// Predicate 输出条件，嵌套的组合条件加括号
+func (r *Renderer) Predicate(pred Predicate) string {
+	switch p := pred.(type) {
+	case Comparison:
+		return r.comparison(p)
+	case Logical:
+		parts := make([]string, len(p.Items))
+		for i, item := range p.Items {
+			parts[i] = r.Predicate(item)
+			if _, nested := item.(Logical); nested {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		op := p.Op
+		if op == "" {
+			op = LogicAnd
+		}
+		return strings.Join(parts, " "+string(op)+" ")
+	default:
+		return ""
+	}
+}
+
+func (r *Renderer) comparison(c Comparison) string {
+	left := r.Expr(c.Left)
+	right := make([]string, len(c.Right))
+	for i, expr := range c.Right {
+		right[i] = r.Expr(expr)
+	}
+
+	switch c.Operator {
+	case OpIsNull, OpIsNotNull:
+		return fmt.Sprintf("%s %s", left, c.Operator)
+	case OpBetween:
+		return fmt.Sprintf("%s BETWEEN %s AND %s", left, right[0], right[1])
+	case OpIn, OpNotIn:
+		return fmt.Sprintf("%s %s (%s)", left, c.Operator, strings.Join(right, ", "))
+	case OpILike, OpNotILike:
+		return r.dialect.ILike(left, right[0], c.Operator == OpNotILike)
+	default:
+		return fmt.Sprintf("%s %s %s", left, c.Operator, right[0])
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// RowIterator 逐行读取查询结果，不把整个结果读入内存。用完后必须调用 Close
+type RowIterator struct {
+	conn    *sql.Conn
+	rows    *sql.Rows
+	cancel  context.CancelFunc
+	columns []ResultColumn
+	row     []any
+	err     error
+}
+
+// queryRows 在 conn 上执行查询，返回的迭代器关闭时一并关闭 conn
+func queryRows(ctx context.Context, conn *sql.Conn, query string) (*RowIterator, error) {
+	// 提前关闭时先取消查询，避免关闭结果集时读完剩余的行
+	ctx, cancel := context.WithCancel(ctx)
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, err
+	}
+	it := &RowIterator{conn: conn, rows: rows, cancel: cancel}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		it.Close()
+		return nil, err
+	}
+	for _, t := range types {
+		it.columns = append(it.columns, ResultColumn{Name: t.Name(), Type: t.DatabaseTypeName()})
+	}
+	return it, nil
+}
+
+// Columns 结果列
+func (it *RowIterator) Columns() []ResultColumn {
+	return it.columns
+}
+
+// Next 读取下一行，没有更多的行或出错时返回 false，错误由 Err 返回
+func (it *RowIterator) Next() bool {
+	if it.err != nil || !it.rows.Next() {
+		return false
+	}
+	it.row, it.err = scanRow(it.rows, it.columns)
+	return it.err == nil
+}
+
+// Row 当前行，NULL 为 nil，文本为 string，二进制为 []byte。返回的切片归调用方所有
+func (it *RowIterator) Row() []any {
+	return it.row
+}
+
+func (it *RowIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.rows.Err()
+}
+
+// Close 中止未读完的查询并释放连接
+func (it *RowIterator) Close() error {
+	it.cancel()
+	it.rows.Close()
+	return it.conn.Close()
+}
+
+// scanRow 读取一行，文本列的字节转为字符串
+func scanRow(rows *sql.Rows, columns []ResultColumn) ([]any, error) {
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if b, ok := v.([]byte); ok {
+			if isBinaryType(columns[i].Type) {
+				values[i] = append([]byte(nil), b...)
+			} else {
+				values[i] = string(b)
+			}
+		}
+	}
+	return values, nil
+}
+
+// isBinaryType 判断类型名是否为二进制类型
+func isBinaryType(typeName string) bool {
+	t := strings.ToUpper(typeName)
+	return strings.Contains(t, "BLOB") || strings.Contains(t, "BINARY") || t == "BYTEA"
+}
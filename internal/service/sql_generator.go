@@ -2,99 +2,236 @@ package service
 
 import (
 	"fmt"
-	"strings"
 )
 
+// JoinInfo 两个表实例之间的连接，SourceInstance/TargetInstance 为空时使用表名作为实例ID
 type JoinInfo struct {
-	SourceTable  string
-	TargetTable  string
-	SourceColumn string
-	TargetColumn string
+	SourceInstance string
+	TargetInstance string
+	SourceTable    string
+	TargetTable    string
+	Conditions     []JoinCondition // 列之间的比较条件，以 AND 组合
+	Filters        []*Condition    // ON 子句中的字面量条件，例如 t2.deleted = 0
+	JoinType       JoinType
 }
 
 type SQLGenerator struct {
-	selectedColumns map[string][]string // 表名 -> 选中的列
+	selectedColumns []SelectColumn // 按输出顺序排列的选中列
 	joins           []JoinInfo
-	mainTable       string            // 主表（第一个表）
-	tableAliases    map[string]string // 表名 -> 别名
+	mainTable       string            // 主表实例ID（FROM 子句中的表）
+	tables          []TableInstance   // 表实例的添加顺序，决定别名编号
+	tableAliases    map[string]string // 实例ID -> 别名
+	where           *FilterGroup      // WHERE 条件
+	having          *FilterGroup      // HAVING 条件
+	distinct        bool
+	limit           int // 0 表示不限制
+	offset          int
+	dialect         Dialect
 }
 
 func NewSQLGenerator() *SQLGenerator {
 	return &SQLGenerator{
-		selectedColumns: make(map[string][]string),
-		tableAliases:    make(map[string]string),
+		tableAliases: make(map[string]string),
+		dialect:      MySQL,
 	}
 }
 
-func (g *SQLGenerator) SetMainTable(tableName string) {
-	g.mainTable = tableName
-	g.tableAliases[tableName] = fmt.Sprintf("t1")
+// SetMainTable 设置FROM子句中的主表实例
+func (g *SQLGenerator) SetMainTable(instanceID string) {
+	g.mainTable = instanceID
+	if _, ok := g.instance(instanceID); !ok {
+		g.AddTable(instanceID)
+	}
+}
+
+// AddTable 登记一张表，实例ID与表名相同
+func (g *SQLGenerator) AddTable(tableName string) {
+	g.AddTableInstance(TableInstance{ID: tableName, Table: tableName})
 }
 
-func (g *SQLGenerator) AddSelectedColumns(tableName string, columns []string) {
-	g.selectedColumns[tableName] = columns
-	if _, exists := g.tableAliases[tableName]; !exists {
-		g.tableAliases[tableName] = fmt.Sprintf("t%d", len(g.tableAliases)+1)
+// AddTableInstance 按顺序登记表实例，已登记的实例会更新表名和别名
+func (g *SQLGenerator) AddTableInstance(inst TableInstance) {
+	if inst.Table == "" {
+		inst.Table = inst.ID
 	}
+	for i, existing := range g.tables {
+		if existing.ID == inst.ID {
+			g.tables[i] = inst
+			return
+		}
+	}
+	g.tables = append(g.tables, inst)
 }
 
-func (g *SQLGenerator) AddJoin(sourceTable, targetTable, sourceColumn, targetColumn string) {
-	g.joins = append(g.joins, JoinInfo{
-		SourceTable:  sourceTable,
-		TargetTable:  targetTable,
-		SourceColumn: sourceColumn,
-		TargetColumn: targetColumn,
-	})
+// AddSelectedColumn 追加一个输出列，输出顺序与调用顺序一致
+func (g *SQLGenerator) AddSelectedColumn(column SelectColumn) {
+	if _, ok := g.instance(column.Table); !ok {
+		g.AddTable(column.Table)
+	}
+	g.selectedColumns = append(g.selectedColumns, column)
+}
 
-	// 确保两个表都有别名
-	if _, exists := g.tableAliases[targetTable]; !exists {
-		g.tableAliases[targetTable] = fmt.Sprintf("t%d", len(g.tableAliases)+1)
+func (g *SQLGenerator) AddSelectedColumns(tableName string, columns []SelectColumn) {
+	for _, col := range columns {
+		col.Table = tableName
+		g.AddSelectedColumn(col)
+	}
+}
+
+// AddJoin 添加两个表实例之间的连接
+func (g *SQLGenerator) AddJoin(join JoinInfo) {
+	if join.JoinType == "" {
+		join.JoinType = LeftJoin
+	}
+	if join.SourceInstance == "" {
+		join.SourceInstance = join.SourceTable
 	}
+	if join.TargetInstance == "" {
+		join.TargetInstance = join.TargetTable
+	}
+	g.joins = append(g.joins, join)
+
+	// 确保两个实例都已登记
+	for _, inst := range []TableInstance{
+		{ID: join.SourceInstance, Table: join.SourceTable},
+		{ID: join.TargetInstance, Table: join.TargetTable},
+	} {
+		if _, ok := g.instance(inst.ID); !ok {
+			g.AddTableInstance(inst)
+		}
+	}
+}
+
+// SetWhere 设置WHERE条件组
+func (g *SQLGenerator) SetWhere(where *FilterGroup) {
+	g.where = where
+}
+
+// SetHaving 设置HAVING条件组
+func (g *SQLGenerator) SetHaving(having *FilterGroup) {
+	g.having = having
+}
+
+// SetDistinct 设置是否去重
+func (g *SQLGenerator) SetDistinct(distinct bool) {
+	g.distinct = distinct
+}
+
+// SetDialect 设置生成语句使用的数据库方言，默认为MySQL
+func (g *SQLGenerator) SetDialect(dialect Dialect) {
+	g.dialect = dialect
 }
 
+// SetLimit 设置返回的行数和偏移量，limit 为 0 表示不限制
+func (g *SQLGenerator) SetLimit(limit, offset int) {
+	g.limit = limit
+	g.offset = offset
+}
+
+// GenerateSQL 构建查询语法树并按当前方言输出SQL
 func (g *SQLGenerator) GenerateSQL() (string, error) {
+	query, err := g.Build()
+	if err != nil {
+		return "", err
+	}
+	return NewRenderer(g.dialect).Render(query)
+}
+
+// TableAliases 返回各表实例在语句中的别名（实例ID -> 别名），在 Build 或 GenerateSQL 之后有效
+func (g *SQLGenerator) TableAliases() map[string]string {
+	aliases := make(map[string]string, len(g.tableAliases))
+	for id, alias := range g.tableAliases {
+		aliases[id] = alias
+	}
+	return aliases
+}
+
+// Build 根据登记的表实例、连接、选中列和条件构建查询语法树，
+// 语法树与方言无关，分页参数的合法性在渲染时检查
+func (g *SQLGenerator) Build() (*Select, error) {
 	if g.mainTable == "" {
-		return "", fmt.Errorf("未设置主表")
+		return nil, fmt.Errorf("未设置主表")
+	}
+
+	if err := g.assignAliases(); err != nil {
+		return nil, err
+	}
+	query := &Select{
+		Distinct: g.distinct,
+		From:     g.tableRef(g.mainTable),
+		Limit:    g.limit,
+		Offset:   g.offset,
 	}
 
-	// 构建SELECT子句
-	var selectClauses []string
-	for tableName, columns := range g.selectedColumns {
-		alias := g.tableAliases[tableName]
-		for _, col := range columns {
-			selectClauses = append(selectClauses,
-				fmt.Sprintf("%s.%s", alias, col))
+	// 按主表重新排列连接，保证每个连接的一侧已经出现在FROM/JOIN中
+	joins, err := orderJoins(g.mainTable, g.instanceIDs(), g.joins)
+	if err != nil {
+		return nil, err
+	}
+	for _, join := range joins {
+		on, err := join.on(g.resolveColumn)
+		if err != nil {
+			return nil, err
 		}
+		NewJoinStrategy(join.JoinType).Apply(query, g.tableRef(join.TargetInstance), on)
 	}
 
-	if len(selectClauses) == 0 {
-		return "", fmt.Errorf("未选择任何列")
+	// 构建SELECT列表，未聚合的列同时作为分组列
+	aggregated := g.hasAggregate()
+	var orders []orderItem
+	for _, col := range g.selectedColumns {
+		expr := g.columnExpr(col)
+		item := SelectItem{Expr: aggregateExpr(col.Aggregate, expr)}
+		if col.needsAlias() {
+			item.Alias = col.OutputName()
+		}
+		query.Columns = append(query.Columns, item)
+		if aggregated && col.Aggregate == AggNone {
+			query.GroupBy = append(query.GroupBy, expr)
+		}
+		if col.Sort != SortNone {
+			orders = append(orders, orderItem{
+				order:    OrderBy{Expr: item.Expr, Direction: col.Sort},
+				priority: col.SortPriority,
+			})
+		}
 	}
 
-	// 构建JOIN子句
-	var joinClauses []string
-	for _, join := range g.joins {
-		sourceAlias := g.tableAliases[join.SourceTable]
-		targetAlias := g.tableAliases[join.TargetTable]
+	if len(query.Columns) == 0 {
+		return nil, fmt.Errorf("未选择任何列")
+	}
+	if err := g.validateOutputNames(); err != nil {
+		return nil, err
+	}
+	sortOrderItems(orders)
+	for _, item := range orders {
+		query.OrderBy = append(query.OrderBy, item.order)
+	}
 
-		joinClauses = append(joinClauses, fmt.Sprintf(
-			"LEFT JOIN %s %s ON %s.%s = %s.%s",
-			join.TargetTable, targetAlias,
-			sourceAlias, join.SourceColumn,
-			targetAlias, join.TargetColumn,
-		))
+	if err := g.validateGrouping(); err != nil {
+		return nil, err
 	}
 
-	// 组装SQL语句
-	sql := fmt.Sprintf("SELECT %s FROM %s %s",
-		strings.Join(selectClauses, ", "),
-		g.mainTable,
-		g.tableAliases[g.mainTable],
-	)
+	// 构建WHERE和HAVING条件
+	if query.Where, err = g.where.predicate(g.resolveColumn); err != nil {
+		return nil, err
+	}
+	if query.Having, err = g.having.predicate(g.resolveColumn); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
 
-	if len(joinClauses) > 0 {
-		sql += " " + strings.Join(joinClauses, " ")
+// resolveColumn 返回表实例中的列在语法树中的引用：别名.列名
+func (g *SQLGenerator) resolveColumn(instanceID, column string) (Expr, error) {
+	alias, ok := g.tableAliases[instanceID]
+	if !ok {
+		return nil, fmt.Errorf("条件引用的表 %s 不在查询中", instanceID)
 	}
+	return ColumnExpr{Qualifier: alias, Column: column}, nil
+}
 
-	return sql + ";", nil
+// tableRef 返回FROM/JOIN中的表实例：表名及其别名
+func (g *SQLGenerator) tableRef(instanceID string) TableRef {
+	return TableRef{Table: g.tableName(instanceID), Alias: g.tableAliases[instanceID]}
 }
@@ -0,0 +1,539 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// ImportedQuery 从SQL还原出的画布内容，列、连接和条件通过实例ID引用表实例
+type ImportedQuery struct {
+	Tables      []TableInstance // 按出现顺序排列，第一个为FROM主表
+	Columns     []SelectColumn  // 按输出顺序排列
+	Joins       []JoinInfo
+	Where       *FilterGroup
+	Having      *FilterGroup
+	Distinct    bool
+	Limit       int
+	Offset      int
+	Unsupported []string // 无法在画布上表示、导入时忽略的部分
+}
+
+// ColumnLookup 返回表的列信息
+type ColumnLookup func(table string) ([]model.Column, error)
+
+// ImportSQL 解析 SELECT 语句并还原为画布内容。columnsOf 用于校验列名、
+// 确定未限定的列属于哪张表以及条件的数据类型，可以为 nil
+func ImportSQL(sql string, d Dialect, columnsOf ColumnLookup) (*ImportedQuery, error) {
+	parsed, err := parseSelect(sql, d)
+	if err != nil {
+		return nil, err
+	}
+	im := &importer{
+		sql:       sql,
+		columnsOf: columnsOf,
+		extraOn:   make(map[int][]*parsedCond),
+		result: &ImportedQuery{
+			Distinct:    parsed.distinct,
+			Limit:       parsed.limit,
+			Offset:      parsed.offset,
+			Unsupported: parsed.problems,
+		},
+	}
+	if err := im.tables(parsed); err != nil {
+		return nil, err
+	}
+	for _, join := range parsed.joins {
+		if join.joinType == RightJoin || join.joinType == FullJoin {
+			im.outerMain = true
+		}
+	}
+	where := im.implicitJoins(parsed)
+	im.joins(parsed)
+	im.columns(parsed)
+	im.orderBy(parsed)
+	im.result.Where = im.filter(where, false)
+	im.result.Having = im.filter(parsed.having, true)
+	im.groupBy(parsed)
+	im.aliasDuplicates()
+	return im.result, nil
+}
+
+// SQL 按导入结果生成语句，用于判断导入前后画布内容是否相同
+func (q *ImportedQuery) SQL(d Dialect) (string, error) {
+	if len(q.Tables) == 0 {
+		return "", fmt.Errorf("未设置主表")
+	}
+	g := NewSQLGenerator()
+	g.SetDialect(d)
+	for _, table := range q.Tables {
+		g.AddTableInstance(table)
+	}
+	g.SetMainTable(q.Tables[0].ID)
+	for _, col := range q.Columns {
+		g.AddSelectedColumn(col)
+	}
+	for _, join := range q.Joins {
+		g.AddJoin(join)
+	}
+	g.SetWhere(q.Where)
+	g.SetHaving(q.Having)
+	g.SetDistinct(q.Distinct)
+	g.SetLimit(q.Limit, q.Offset)
+	return g.GenerateSQL()
+}
+
+// importer 把解析出的语句转换为画布内容
+type importer struct {
+	sql       string
+	columnsOf ColumnLookup
+	result    *ImportedQuery
+	instances []importedTable
+	extraOn   map[int][]*parsedCond // 从 WHERE 移到逗号连接上的条件，键为连接序号
+	outputs   []int                 // SQL 中每个输出位置对应的列序号，未导入的为 -1
+	texts     []string              // 每个导入列的原文，用于匹配 ORDER BY/GROUP BY
+	used      map[string]bool       // 实例ID/列名，避免计算列与已有列重名
+	outerMain bool                  // 有 RIGHT JOIN 或 FULL JOIN，主表可能没有对应的行
+}
+
+// importedTable 语句中的一个表实例
+type importedTable struct {
+	id      string
+	table   string
+	alias   string
+	columns []model.Column // columnsOf 为 nil 时为空
+}
+
+func (im *importer) problem(format string, args ...any) {
+	im.result.Unsupported = append(im.result.Unsupported, fmt.Sprintf(format, args...))
+}
+
+func (im *importer) text(start, end int) string {
+	return strings.Join(strings.Fields(im.sql[start:end]), " ")
+}
+
+// tables 登记 FROM 和 JOIN 中的表实例，实例ID与画布的规则一致：表名、表名#2...
+func (im *importer) tables(parsed *parsedQuery) error {
+	refs := []parsedTable{parsed.from}
+	for _, join := range parsed.joins {
+		refs = append(refs, join.table)
+	}
+
+	count := make(map[string]int)
+	aliases := make(map[string]bool)
+	im.used = make(map[string]bool)
+	for _, ref := range refs {
+		if ref.schema != "" {
+			im.problem("忽略了表 %s 的库名 %s", ref.name, ref.schema)
+		}
+		key := strings.ToLower(ref.alias)
+		if ref.alias != "" && aliases[key] {
+			return fmt.Errorf("表别名 %s 重复", ref.alias)
+		}
+		aliases[key] = true
+
+		inst := importedTable{id: ref.name, table: ref.name, alias: ref.alias}
+		if count[ref.name]++; count[ref.name] > 1 {
+			inst.id = fmt.Sprintf("%s#%d", ref.name, count[ref.name])
+		}
+		if im.columnsOf != nil {
+			columns, err := im.columnsOf(ref.name)
+			if err != nil {
+				return err
+			}
+			if len(columns) == 0 {
+				return fmt.Errorf("表 %s 不存在或没有列", ref.name)
+			}
+			inst.columns = columns
+			for _, col := range columns {
+				im.used[inst.id+"/"+strings.ToLower(col.Name)] = true
+			}
+		}
+		im.instances = append(im.instances, inst)
+		im.result.Tables = append(im.result.Tables, TableInstance{ID: inst.id, Table: inst.table, Alias: inst.alias})
+	}
+	return nil
+}
+
+// resolve 确定列引用所属的表实例，返回实例ID和列信息
+func (im *importer) resolve(ref ColumnExpr) (string, model.Column, error) {
+	var candidates []importedTable
+	if ref.Qualifier != "" {
+		for _, inst := range im.instances {
+			if strings.EqualFold(inst.alias, ref.Qualifier) ||
+				inst.alias == "" && strings.EqualFold(inst.table, ref.Qualifier) {
+				candidates = append(candidates, inst)
+			}
+		}
+		if len(candidates) == 0 {
+			return "", model.Column{}, fmt.Errorf("未知的表 %s", ref.Qualifier)
+		}
+	} else {
+		for _, inst := range im.instances {
+			if inst.columns == nil && len(im.instances) == 1 || findColumn(inst.columns, ref.Column) != nil {
+				candidates = append(candidates, inst)
+			}
+		}
+		switch {
+		case len(candidates) == 0 && im.columnsOf == nil:
+			return "", model.Column{}, fmt.Errorf("列 %s 未指定表", ref.Column)
+		case len(candidates) == 0:
+			return "", model.Column{}, fmt.Errorf("找不到列 %s", ref.Column)
+		case len(candidates) > 1:
+			return "", model.Column{}, fmt.Errorf("列 %s 有歧义", ref.Column)
+		}
+	}
+
+	inst := candidates[0]
+	if inst.columns == nil {
+		return inst.id, model.Column{Name: ref.Column}, nil
+	}
+	col := findColumn(inst.columns, ref.Column)
+	if col == nil {
+		return "", model.Column{}, fmt.Errorf("表 %s 中没有列 %s", inst.table, ref.Column)
+	}
+	return inst.id, *col, nil
+}
+
+// instanceTable 返回实例对应的表名
+func (im *importer) instanceTable(id string) string {
+	for _, inst := range im.instances {
+		if inst.id == id {
+			return inst.table
+		}
+	}
+	return id
+}
+
+func findColumn(columns []model.Column, name string) *model.Column {
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, name) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// columns 把 SELECT 列表转换为选中的列，* 展开为表的所有列，其他表达式转换为计算列
+func (im *importer) columns(parsed *parsedQuery) {
+	for _, item := range parsed.items {
+		if item.star != "" {
+			im.starColumns(item.star)
+			continue
+		}
+
+		e, agg := item.expr, AggNone
+		if a, ok := e.node.(AggregateExpr); ok {
+			e, agg = e.args[0], a.Func
+		}
+		text := im.text(item.expr.start, item.expr.end)
+		var col SelectColumn
+		var err error
+		if e.countAll {
+			agg = AggCount
+			var instance string
+			var meta model.Column
+			if instance, meta, err = im.countAll(true); err == nil {
+				col, err = im.selectColumn(instance, meta.Name, item.alias)
+			}
+		} else if ref, ok := e.node.(ColumnExpr); ok {
+			col, err = im.plainColumn(ref, item.alias)
+		} else {
+			col, err = im.expressionColumn(e, item.alias)
+		}
+		if err != nil {
+			im.problem("输出列 %s: %v", text, err)
+			im.outputs = append(im.outputs, -1)
+			continue
+		}
+		// 聚合列不设别名时由数据库生成列名，因此与列名相同的别名也要保留
+		col.Aggregate = agg
+		if agg != AggNone && col.Alias == "" && col.Expression == "" {
+			col.Alias = item.alias
+		}
+		im.addColumn(col, text)
+	}
+}
+
+func (im *importer) addColumn(col SelectColumn, text string) {
+	im.outputs = append(im.outputs, len(im.result.Columns))
+	im.result.Columns = append(im.result.Columns, col)
+	im.texts = append(im.texts, strings.ToUpper(text))
+}
+
+// aliasDuplicates 画布要求输出列名唯一，自连接中的 e.name 和 m.name 这样同名的列
+// 以表别名为前缀设置别名，例如 e_name、m_name。已有别名的列保持不变
+func (im *importer) aliasDuplicates() {
+	// 与生成时的检查一致，未设置别名的聚合列由数据库生成列名，不参与比较
+	named := func(col SelectColumn) bool {
+		return col.Aggregate == AggNone || col.needsAlias()
+	}
+	counts := make(map[string]int) // 小写的输出名 -> 列数
+	for _, col := range im.result.Columns {
+		if named(col) {
+			counts[strings.ToLower(col.OutputName())]++
+		}
+	}
+	for i := range im.result.Columns {
+		col := &im.result.Columns[i]
+		if col.Alias != "" || !named(*col) || counts[strings.ToLower(col.Name)] < 2 {
+			continue
+		}
+		prefix := im.instanceTable(col.Table)
+		for _, inst := range im.instances {
+			if inst.id == col.Table && inst.alias != "" {
+				prefix = inst.alias
+			}
+		}
+		name := prefix + "_" + col.Name
+		if ValidateAlias(name) != nil {
+			name = col.Name
+		}
+		alias := name
+		for n := 2; counts[strings.ToLower(alias)] > 0; n++ {
+			alias = fmt.Sprintf("%s_%d", name, n)
+		}
+		counts[strings.ToLower(alias)]++
+		col.Alias = alias
+	}
+}
+
+// countAll 把 COUNT(*) 转换为对主表一个非空列的 COUNT，两者结果相同。
+// 优先使用主键；output 为输出列时跳过已输出的列，因为同一列不能再次勾选
+func (im *importer) countAll(output bool) (string, model.Column, error) {
+	if im.outerMain {
+		return "", model.Column{}, fmt.Errorf("有 RIGHT JOIN 或 FULL JOIN 时 COUNT(*) 无法转换为列的计数")
+	}
+	main := im.instances[0]
+	if main.columns == nil {
+		return "", model.Column{}, fmt.Errorf("缺少表 %s 的列信息，无法转换 COUNT(*)", main.table)
+	}
+	var candidate *model.Column
+	for i := range main.columns {
+		col := &main.columns[i]
+		if col.Nullable || output && im.selected(main.id, col.Name) {
+			continue
+		}
+		if col.Key == "PRI" {
+			return main.id, *col, nil
+		}
+		if candidate == nil {
+			candidate = col
+		}
+	}
+	if candidate == nil {
+		return "", model.Column{}, fmt.Errorf("表 %s 没有可用于 COUNT(*) 的非空列", main.table)
+	}
+	return main.id, *candidate, nil
+}
+
+// selected 判断表实例的列是否已经输出
+func (im *importer) selected(instance, name string) bool {
+	for _, existing := range im.result.Columns {
+		if existing.Table == instance && existing.Name == name && existing.Expression == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// starColumns 展开 * 或 限定名.*，需要知道表的列
+func (im *importer) starColumns(qualifier string) {
+	for _, inst := range im.instances {
+		if qualifier != "*" && !strings.EqualFold(inst.alias, qualifier) &&
+			!(inst.alias == "" && strings.EqualFold(inst.table, qualifier)) {
+			continue
+		}
+		if inst.columns == nil {
+			im.problem("缺少表 %s 的列信息，无法展开 *", inst.table)
+			continue
+		}
+		for _, column := range inst.columns {
+			col, err := im.selectColumn(inst.id, column.Name, "")
+			if err != nil {
+				im.problem("输出列 %s.%s: %v", inst.id, column.Name, err)
+				continue
+			}
+			im.addColumn(col, inst.id+"."+column.Name)
+		}
+	}
+}
+
+// plainColumn 转换对列的直接引用
+func (im *importer) plainColumn(ref ColumnExpr, alias string) (SelectColumn, error) {
+	instance, meta, err := im.resolve(ref)
+	if err != nil {
+		return SelectColumn{}, err
+	}
+	return im.selectColumn(instance, meta.Name, alias)
+}
+
+// selectColumn 输出表实例的一列，同一列在画布上只能勾选一次
+func (im *importer) selectColumn(instance, name, alias string) (SelectColumn, error) {
+	if im.selected(instance, name) {
+		return SelectColumn{}, fmt.Errorf("列 %s.%s 重复输出", instance, name)
+	}
+	col := SelectColumn{Table: instance, Name: name}
+	if !strings.EqualFold(alias, name) {
+		col.Alias = alias
+	}
+	return col, nil
+}
+
+// expressionColumn 把只引用一个表实例的表达式转换为该表上的计算列，
+// 列引用替换为 {列名} 占位符
+func (im *importer) expressionColumn(e *parsedExpr, alias string) (SelectColumn, error) {
+	if e.opaque {
+		return SelectColumn{}, fmt.Errorf("包含子查询")
+	}
+	if len(e.refs) == 0 {
+		return SelectColumn{}, fmt.Errorf("没有引用任何列，无法放到表上")
+	}
+
+	var b strings.Builder
+	instance := ""
+	pos := e.start
+	for _, ref := range e.refs {
+		id, meta, err := im.resolve(ref.ColumnExpr)
+		if err != nil {
+			return SelectColumn{}, err
+		}
+		if instance != "" && id != instance {
+			return SelectColumn{}, fmt.Errorf("引用了多个表")
+		}
+		instance = id
+		b.WriteString(im.sql[pos:ref.start])
+		b.WriteString(Placeholder(meta.Name))
+		pos = ref.end
+	}
+	b.WriteString(im.sql[pos:e.end])
+
+	// 计算列在表内按名称区分，与已有列重名时另起名称并以别名输出
+	name := alias
+	if name == "" || ValidateAlias(name) != nil {
+		name = "expr"
+	}
+	unique := name
+	for n := 2; im.used[instance+"/"+strings.ToLower(unique)]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	im.used[instance+"/"+strings.ToLower(unique)] = true
+
+	col := SelectColumn{Table: instance, Name: unique, Expression: b.String()}
+	if alias != "" && alias != unique {
+		col.Alias = alias
+	}
+	return col, nil
+}
+
+// orderBy 把 ORDER BY 转换为输出列的排序方向和优先级，
+// 排序项可以是列序号、输出别名或与输出列相同的表达式
+func (im *importer) orderBy(parsed *parsedQuery) {
+	for i, item := range parsed.orderBy {
+		index := im.outputIndex(item.expr)
+		text := im.text(item.expr.start, item.expr.end)
+		if index < 0 {
+			im.problem("ORDER BY %s 不是输出列", text)
+			continue
+		}
+		col := &im.result.Columns[index]
+		if col.Sort != SortNone {
+			continue
+		}
+		col.Sort = item.direction
+		col.SortPriority = i + 1
+	}
+}
+
+// outputIndex 查找表达式对应的输出列，未找到时返回 -1
+func (im *importer) outputIndex(e *parsedExpr) int {
+	if lit, ok := e.node.(LiteralExpr); ok {
+		if n, err := strconv.Atoi(lit.Value); err == nil && n >= 1 && n <= len(im.outputs) {
+			return im.outputs[n-1]
+		}
+		return -1
+	}
+	if ref, ok := e.node.(ColumnExpr); ok && ref.Qualifier == "" {
+		for i, col := range im.result.Columns {
+			if col.Alias != "" && strings.EqualFold(col.Alias, ref.Column) ||
+				col.Expression != "" && strings.EqualFold(col.Name, ref.Column) {
+				return i
+			}
+		}
+	}
+
+	inner, agg := e, AggNone
+	if a, ok := e.node.(AggregateExpr); ok {
+		inner, agg = e.args[0], a.Func
+	}
+	if ref, ok := inner.node.(ColumnExpr); ok {
+		if instance, meta, err := im.resolve(ref); err == nil {
+			for i, col := range im.result.Columns {
+				if col.Table == instance && col.Name == meta.Name && col.Expression == "" && col.Aggregate == agg {
+					return i
+				}
+			}
+		}
+	}
+
+	text := strings.ToUpper(im.text(e.start, e.end))
+	for i, columnText := range im.texts {
+		if columnText == text {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupBy 画布按未聚合的输出列分组，GROUP BY 与之不一致时报告
+func (im *importer) groupBy(parsed *parsedQuery) {
+	aggregated := false
+	for _, col := range im.result.Columns {
+		if col.Aggregate != AggNone {
+			aggregated = true
+		}
+	}
+	im.result.Having.Walk(func(cond *Condition) {
+		if cond.Aggregate != AggNone {
+			aggregated = true
+		}
+	})
+
+	implied := make(map[int]bool)
+	if aggregated {
+		for i, col := range im.result.Columns {
+			if col.Aggregate == AggNone {
+				implied[i] = true
+			}
+		}
+	}
+
+	if len(parsed.groupBy) == 0 {
+		if len(implied) > 0 {
+			im.problem("语句没有 GROUP BY，生成时会按未聚合的输出列分组")
+		}
+		return
+	}
+	var texts []string
+	grouped := make(map[int]bool)
+	for _, expr := range parsed.groupBy {
+		texts = append(texts, im.text(expr.start, expr.end))
+		if index := im.outputIndex(expr); index >= 0 {
+			grouped[index] = true
+		} else {
+			grouped[-1] = true
+		}
+	}
+	if len(grouped) != len(implied) {
+		im.problem("GROUP BY %s 与未聚合的输出列不一致，生成时按未聚合的输出列分组", strings.Join(texts, ", "))
+		return
+	}
+	for index := range grouped {
+		if !implied[index] {
+			im.problem("GROUP BY %s 与未聚合的输出列不一致，生成时按未聚合的输出列分组", strings.Join(texts, ", "))
+			return
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// flippedOperators 交换比较两侧时对应的运算符
+var flippedOperators = map[FilterOperator]FilterOperator{
+	OpEqual: OpEqual, OpNotEqual: OpNotEqual,
+	OpLess: OpGreater, OpLessEqual: OpGreaterEqual, OpGreater: OpLess, OpGreaterEqual: OpLessEqual,
+}
+
+// implicitJoins 把 WHERE 中逗号连接的表之间的列比较移到对应的连接上，返回剩余的 WHERE 条件
+func (im *importer) implicitJoins(parsed *parsedQuery) *parsedCond {
+	comma := false
+	for _, join := range parsed.joins {
+		comma = comma || join.comma
+	}
+	if !comma || parsed.where == nil {
+		return parsed.where
+	}
+
+	var rest []*parsedCond
+	for _, item := range conjuncts(parsed.where) {
+		if k, ok := im.implicitJoin(parsed, item); ok {
+			im.extraOn[k] = append(im.extraOn[k], item)
+			continue
+		}
+		rest = append(rest, item)
+	}
+	switch len(rest) {
+	case 0:
+		return nil
+	case 1:
+		return rest[0]
+	default:
+		return &parsedCond{logic: LogicAnd, items: rest, start: parsed.where.start, end: parsed.where.end}
+	}
+}
+
+// implicitJoin 判断条件是否为逗号连接的表与之前的表之间的列比较，返回连接序号
+func (im *importer) implicitJoin(parsed *parsedQuery, c *parsedCond) (int, bool) {
+	indexes, ok := im.columnComparison(c)
+	if !ok {
+		return 0, false
+	}
+	last, distinct := 0, false
+	for _, index := range indexes {
+		distinct = distinct || index != indexes[0]
+		if index > last {
+			last = index
+		}
+	}
+	if !distinct || last == 0 || !parsed.joins[last-1].comma {
+		return 0, false
+	}
+	return last - 1, true
+}
+
+// columnComparison 判断条件是否为列之间的比较，返回各列所属表实例的序号
+func (im *importer) columnComparison(c *parsedCond) ([]int, bool) {
+	if c.logic != "" || c.problem != "" || len(c.right) == 0 {
+		return nil, false
+	}
+	var indexes []int
+	for _, e := range append([]*parsedExpr{c.left}, c.right...) {
+		ref, ok := e.node.(ColumnExpr)
+		if !ok {
+			return nil, false
+		}
+		id, _, err := im.resolve(ref)
+		if err != nil {
+			return nil, false
+		}
+		indexes = append(indexes, im.instanceIndex(id))
+	}
+	return indexes, true
+}
+
+func (im *importer) instanceIndex(id string) int {
+	for i, inst := range im.instances {
+		if inst.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// conjuncts 拆分以 AND 组合的条件
+func conjuncts(c *parsedCond) []*parsedCond {
+	if c == nil {
+		return nil
+	}
+	if c.logic != LogicAnd {
+		return []*parsedCond{c}
+	}
+	var items []*parsedCond
+	for _, item := range c.items {
+		items = append(items, conjuncts(item)...)
+	}
+	return items
+}
+
+// joins 把 JOIN 子句转换为连线，ON 条件中的列比较成为连接条件，与常量的比较成为连线上的过滤条件
+func (im *importer) joins(parsed *parsedQuery) {
+	for k, pj := range parsed.joins {
+		target := k + 1
+		conds := append(conjuncts(pj.on), im.extraOn[k]...)
+		source := im.joinSource(target, pj.using, conds)
+		join := JoinInfo{
+			SourceInstance: im.instances[source].id,
+			TargetInstance: im.instances[target].id,
+			SourceTable:    im.instances[source].table,
+			TargetTable:    im.instances[target].table,
+			JoinType:       pj.joinType,
+		}
+
+		for _, column := range pj.using {
+			join.Conditions = append(join.Conditions, JoinCondition{
+				Left:     ColumnRef{Instance: join.SourceInstance, Column: column},
+				Operator: OpEqual,
+				Right:    ColumnRef{Instance: join.TargetInstance, Column: column},
+			})
+		}
+		for _, c := range conds {
+			if err := im.joinCondition(&join, c); err != nil {
+				im.problem("连接 %s 的条件 %s: %v", join.TargetInstance, im.text(c.start, c.end), err)
+			}
+		}
+
+		empty := len(join.Conditions) == 0 && len(join.Filters) == 0
+		switch {
+		case pj.comma && !empty:
+			join.JoinType = InnerJoin
+		case empty && join.JoinType != CrossJoin:
+			im.problem("连接 %s 没有可用的条件，按 CROSS JOIN 导入", join.TargetInstance)
+			join.JoinType = CrossJoin
+		}
+		im.result.Joins = append(im.result.Joins, join)
+	}
+}
+
+// joinSource 确定连线的起点：条件中引用的第一个之前的表实例，
+// USING 时为之前含有该列的最近的表实例，都没有时为主表
+func (im *importer) joinSource(target int, using []string, conds []*parsedCond) int {
+	for _, c := range conds {
+		indexes, _ := im.columnComparison(c)
+		for _, index := range indexes {
+			if index < target {
+				return index
+			}
+		}
+	}
+	if len(using) > 0 {
+		for i := target - 1; i >= 0; i-- {
+			if im.instances[i].columns == nil || findColumn(im.instances[i].columns, using[0]) != nil {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// joinCondition 把单个ON条件加入连线
+func (im *importer) joinCondition(join *JoinInfo, c *parsedCond) error {
+	if c.logic != "" {
+		return fmt.Errorf("连线上的条件只能以 AND 组合")
+	}
+	if c.problem != "" {
+		return fmt.Errorf("%s", c.problem)
+	}
+
+	indexes, ok := im.columnComparison(c)
+	if !ok {
+		filter, err := im.condition(c, false)
+		if err != nil {
+			return err
+		}
+		if filter.Table != join.SourceInstance && filter.Table != join.TargetInstance {
+			return fmt.Errorf("引用了连线两端以外的表")
+		}
+		join.Filters = append(join.Filters, filter)
+		return nil
+	}
+
+	var refs []ColumnRef
+	for i, e := range append([]*parsedExpr{c.left}, c.right...) {
+		id := im.instances[indexes[i]].id
+		if id != join.SourceInstance && id != join.TargetInstance {
+			return fmt.Errorf("引用了连线两端以外的表")
+		}
+		_, meta, _ := im.resolve(e.node.(ColumnExpr))
+		refs = append(refs, ColumnRef{Instance: id, Column: meta.Name})
+	}
+	if !joinOperator(c.op) {
+		return fmt.Errorf("连接条件不支持运算符 %s", c.op)
+	}
+	cond := JoinCondition{Left: refs[0], Operator: c.op, Right: refs[1]}
+	if c.op == OpBetween {
+		cond.Right2 = refs[2]
+	}
+	join.Conditions = append(join.Conditions, cond)
+	return nil
+}
+
+func joinOperator(op FilterOperator) bool {
+	for _, candidate := range JoinOperators() {
+		if candidate == op {
+			return true
+		}
+	}
+	return false
+}
+
+// filter 把 WHERE/HAVING 条件转换为条件组，无法表示的条件逐个报告
+func (im *importer) filter(c *parsedCond, having bool) *FilterGroup {
+	if c == nil {
+		return nil
+	}
+	group := NewFilterGroup(LogicAnd)
+	items := []*parsedCond{c}
+	if c.logic != "" {
+		group.Logic, items = c.logic, c.items
+	}
+	for _, item := range items {
+		if item.logic != "" {
+			if child := im.filter(item, having); child != nil {
+				group.Groups = append(group.Groups, child)
+			}
+			continue
+		}
+		cond, err := im.condition(item, having)
+		if err != nil {
+			im.problem("条件 %s: %v", im.text(item.start, item.end), err)
+			continue
+		}
+		group.Conditions = append(group.Conditions, cond)
+	}
+	if group.IsEmpty() {
+		return nil
+	}
+	return group
+}
+
+// condition 把 列 运算符 常量 形式的比较转换为过滤条件，
+// 常量在左侧时交换两侧，Oracle 的 UPPER(x) LIKE UPPER(y) 还原为 ILIKE
+func (im *importer) condition(c *parsedCond, having bool) (*Condition, error) {
+	if c.problem != "" {
+		return nil, fmt.Errorf("%s", c.problem)
+	}
+	left, right, op := c.left, c.right, c.op
+	if flipped, ok := flippedOperators[op]; ok && constant(left) && !constant(right[0]) {
+		left, right, op = right[0], []*parsedExpr{left}, flipped
+	}
+	if (op == OpLike || op == OpNotLike) && upperArg(left) != nil && upperArg(right[0]) != nil {
+		left, right = upperArg(left), []*parsedExpr{upperArg(right[0])}
+		op = negated(OpILike, OpNotILike, op == OpNotLike)
+	}
+
+	agg := AggNone
+	if _, ok := left.node.(AggregateExpr); ok || left.countAll {
+		if !having {
+			return nil, fmt.Errorf("聚合函数只能用于 HAVING 条件")
+		}
+	}
+	var instance string
+	var meta model.Column
+	var err error
+	if a, ok := left.node.(AggregateExpr); ok {
+		left, agg = left.args[0], a.Func
+	}
+	if left.countAll {
+		agg = AggCount
+		instance, meta, err = im.countAll(false)
+	} else if ref, ok := left.node.(ColumnExpr); ok {
+		instance, meta, err = im.resolve(ref)
+	} else {
+		return nil, fmt.Errorf("左侧不是列")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cond := &Condition{Table: instance, Column: meta.Name, DataType: meta.Type, Aggregate: agg, Operator: op}
+	params := 0
+	for _, value := range right {
+		switch node := value.node.(type) {
+		case ParamExpr:
+			params++
+			cond.Values = append(cond.Values, "?")
+		case LiteralExpr:
+			cond.Values = append(cond.Values, node.Value)
+			// 没有列信息时按字面量的类型输出，避免数值被加上引号
+			if cond.DataType == "" && agg == AggNone {
+				cond.DataType = node.DataType
+			}
+		default:
+			if value.null {
+				return nil, fmt.Errorf("与 NULL 比较应使用 IS NULL")
+			}
+			return nil, fmt.Errorf("比较值 %s 不是常量", im.text(value.start, value.end))
+		}
+	}
+	if params > 0 && params != len(right) {
+		return nil, fmt.Errorf("参数占位符不能与常量混用")
+	}
+	cond.Parameter = params > 0
+	return cond, nil
+}
+
+func constant(e *parsedExpr) bool {
+	switch e.node.(type) {
+	case LiteralExpr, ParamExpr:
+		return true
+	}
+	return false
+}
+
+// upperArg 返回 UPPER(x) 的参数 x，不是 UPPER 调用时返回 nil
+func upperArg(e *parsedExpr) *parsedExpr {
+	if e.fn == "UPPER" && len(e.args) == 1 {
+		return e.args[0]
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lowSqlGen/internal/model"
+)
+
+// testColumns 测试用的表结构
+var testColumns = map[string][]model.Column{
+	"emp": {
+		{Name: "id", Type: "int", Key: "PRI"},
+		{Name: "name", Type: "varchar(64)", Nullable: true},
+		{Name: "mgr", Type: "int", Nullable: true},
+		{Name: "dept_id", Type: "int", Nullable: true},
+	},
+	"dept": {
+		{Name: "id", Type: "int", Key: "PRI"},
+		{Name: "name", Type: "varchar(64)"},
+	},
+	"a": {{Name: "id", Type: "int"}, {Name: "x", Type: "int"}},
+	"b": {{Name: "id", Type: "int"}, {Name: "y", Type: "int"}},
+}
+
+func lookupTestColumns(table string) ([]model.Column, error) {
+	return testColumns[table], nil
+}
+
+// importAndGenerate 导入语句后按导入结果重新生成
+func importAndGenerate(t *testing.T, sql string, d Dialect) string {
+	t.Helper()
+	q, err := ImportSQL(sql, d, lookupTestColumns)
+	if err != nil {
+		t.Fatalf("ImportSQL(%q): %v", sql, err)
+	}
+	if len(q.Unsupported) > 0 {
+		t.Fatalf("ImportSQL(%q) unsupported: %v", sql, q.Unsupported)
+	}
+	got, err := q.SQL(d)
+	if err != nil {
+		t.Fatalf("SQL() for %q: %v", sql, err)
+	}
+	return got
+}
+
+// 同名的输出列导入后以表别名为前缀设置别名
+func TestImportDuplicateOutputNames(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "self join",
+			sql:  "SELECT e.name, m.name FROM emp e JOIN emp m ON e.mgr = m.id",
+			want: "SELECT `e`.`name` AS `e_name`, `m`.`name` AS `m_name` FROM `emp` `e` INNER JOIN `emp` `m` ON `e`.`mgr` = `m`.`id`;",
+		},
+		{
+			name: "star over tables sharing a column",
+			sql:  "SELECT * FROM a, b",
+			want: "SELECT `t1`.`id` AS `a_id`, `t1`.`x`, `t2`.`id` AS `b_id`, `t2`.`y` FROM `a` `t1` CROSS JOIN `b` `t2`;",
+		},
+		{
+			name: "unaliased aggregate does not collide",
+			sql:  "SELECT e.name, COUNT(m.name) FROM emp e JOIN emp m ON e.mgr = m.id GROUP BY e.name",
+			want: "SELECT `e`.`name`, COUNT(`m`.`name`) FROM `emp` `e` INNER JOIN `emp` `m` ON `e`.`mgr` = `m`.`id` GROUP BY `e`.`name`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := importAndGenerate(t, tt.sql, MySQL)
+			if got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+			// 生成的语句再次导入后不变
+			if again := importAndGenerate(t, got, MySQL); again != got {
+				t.Errorf("second round trip changed the query:\n got %s\nwant %s", again, got)
+			}
+		})
+	}
+}
+
+// COUNT(*) 导入为主表非空列的 COUNT，优先使用主键
+func TestImportCountAll(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "primary key",
+			sql:  "SELECT COUNT(*) FROM emp",
+			want: "SELECT COUNT(`t1`.`id`) FROM `emp` `t1`;",
+		},
+		{
+			name: "having",
+			sql:  "SELECT d.name, COUNT(*) AS n FROM dept d GROUP BY d.name HAVING COUNT(*) > 1",
+			want: "SELECT `d`.`name`, COUNT(`d`.`id`) AS `n` FROM `dept` `d` GROUP BY `d`.`name` HAVING COUNT(`d`.`id`) > 1;",
+		},
+		{
+			name: "primary key already selected",
+			sql:  "SELECT d.id, COUNT(*) FROM dept d GROUP BY d.id",
+			want: "SELECT `d`.`id`, COUNT(`d`.`name`) FROM `dept` `d` GROUP BY `d`.`id`;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importAndGenerate(t, tt.sql, MySQL); got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	// RIGHT JOIN 时主表可能没有对应的行，不能转换
+	q, err := ImportSQL("SELECT COUNT(*) FROM emp e RIGHT JOIN dept d ON e.dept_id = d.id", MySQL, lookupTestColumns)
+	if err != nil {
+		t.Fatalf("ImportSQL: %v", err)
+	}
+	if len(q.Unsupported) == 0 {
+		t.Errorf("COUNT(*) with RIGHT JOIN was imported: %+v", q.Columns)
+	}
+}
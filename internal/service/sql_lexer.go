@@ -0,0 +1,211 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind 词法单元的类型
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // 标识符或关键字
+	tokQuoted           // 加引号的标识符
+	tokString           // 字符串字面量
+	tokNumber           // 数值字面量
+	tokParam            // 参数占位符：?、$1、:name
+	tokSymbol           // 运算符和标点
+)
+
+// token 词法单元，text 为去掉引号和转义后的内容，pos/end 为在原文中的位置
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// is 判断是否为指定的关键字或符号，关键字不区分大小写
+func (t token) is(text string) bool {
+	switch t.kind {
+	case tokIdent:
+		return strings.EqualFold(t.text, text)
+	case tokSymbol:
+		return t.text == text
+	default:
+		return false
+	}
+}
+
+// sqlSymbols 多字符的运算符，按长度优先匹配
+var sqlSymbols = []string{"<=", ">=", "<>", "!=", "||", "::"}
+
+// tokenize 把SQL拆分为词法单元。MySQL 的字符串中反斜杠为转义符，# 开始单行注释。
+// lenient 为 true 时无法识别的字符作为符号返回，用于只需要区分字符串、注释和语句分隔符的检查
+func tokenize(sql string, d Dialect, lenient bool) ([]token, error) {
+	_, mysql := d.(mysqlDialect)
+	backslashEscapes := mysql
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(sql[i:], "--"), c == '#' && mysql:
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("注释未结束")
+			}
+			i += end + 4
+		case c == '\'':
+			text, end, err := scanString(sql, i, backslashEscapes)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: i, end: end})
+			i = end
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			text, end, err := scanQuoted(sql, i, closing)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokQuoted, text: text, pos: i, end: end})
+			i = end
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			end := scanNumber(sql, i)
+			tokens = append(tokens, token{kind: tokNumber, text: sql[i:end], pos: i, end: end})
+			i = end
+		case c == '?':
+			tokens = append(tokens, token{kind: tokParam, text: "?", pos: i, end: i + 1})
+			i++
+		case (c == '$' || c == ':' || c == '@') && i+1 < len(sql) && (isIdentStart(sql[i+1:]) || c != '@' && sql[i+1] >= '0' && sql[i+1] <= '9'):
+			end := scanIdent(sql, i+1)
+			tokens = append(tokens, token{kind: tokParam, text: sql[i:end], pos: i, end: end})
+			i = end
+		case isIdentStart(sql[i:]):
+			end := scanIdent(sql, i)
+			tokens = append(tokens, token{kind: tokIdent, text: sql[i:end], pos: i, end: end})
+			i = end
+		default:
+			symbol := string(c)
+			for _, s := range sqlSymbols {
+				if strings.HasPrefix(sql[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if !strings.Contains("(),.*=<>+-/%;|:", symbol[:1]) {
+				if !lenient {
+					return nil, fmt.Errorf("无法识别的字符 %q（位置 %d）", symbol, i)
+				}
+				_, size := utf8.DecodeRuneInString(sql[i:])
+				symbol = sql[i : i+size]
+			}
+			tokens = append(tokens, token{kind: tokSymbol, text: symbol, pos: i, end: i + len(symbol)})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(sql), end: len(sql)}), nil
+}
+
+// scanString 读取单引号字符串，两个单引号表示一个单引号
+func scanString(sql string, start int, backslashEscapes bool) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\\' && backslashEscapes && i+1 < len(sql):
+			i++
+			b.WriteByte(unescapeByte(sql[i]))
+		case c == '\'':
+			if i+1 < len(sql) && sql[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("字符串未结束（位置 %d）", start)
+}
+
+// unescapeByte MySQL 字符串中反斜杠转义的字符
+func unescapeByte(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return c
+	}
+}
+
+// scanQuoted 读取加引号的标识符，连续两个结束引号表示引号本身
+func scanQuoted(sql string, start int, closing byte) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != closing {
+			b.WriteByte(sql[i])
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == closing {
+			b.WriteByte(closing)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("标识符引号未结束（位置 %d）", start)
+}
+
+func scanNumber(sql string, start int) int {
+	i := start
+	for i < len(sql) && (sql[i] >= '0' && sql[i] <= '9' || sql[i] == '.') {
+		i++
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+			for i = j; i < len(sql) && sql[i] >= '0' && sql[i] <= '9'; i++ {
+			}
+		}
+	}
+	return i
+}
+
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func scanIdent(sql string, start int) int {
+	i := start
+	for i < len(sql) {
+		r, size := utf8.DecodeRuneInString(sql[i:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
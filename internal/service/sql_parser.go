@@ -0,0 +1,526 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsedQuery 解析出的 SELECT 语句，保留原文位置以便还原计算列和报告问题
+type parsedQuery struct {
+	distinct bool
+	items    []parsedItem
+	from     parsedTable
+	joins    []parsedJoin
+	where    *parsedCond
+	groupBy  []*parsedExpr
+	having   *parsedCond
+	orderBy  []parsedOrder
+	limit    int
+	offset   int
+	problems []string // 能识别但无法在画布上表示的部分
+}
+
+// parsedItem SELECT 列表中的一项，star 非空时表示 * 或 限定名.*
+type parsedItem struct {
+	expr  *parsedExpr
+	star  string
+	alias string
+}
+
+// parsedTable FROM/JOIN 中的表
+type parsedTable struct {
+	schema string
+	name   string
+	alias  string
+}
+
+// parsedJoin JOIN 子句，comma 表示以逗号分隔的旧式连接
+type parsedJoin struct {
+	joinType JoinType
+	table    parsedTable
+	on       *parsedCond
+	using    []string
+	comma    bool
+}
+
+type parsedOrder struct {
+	expr      *parsedExpr
+	direction SortDirection
+}
+
+// parser 递归下降解析 SELECT 语句
+type parser struct {
+	sql      string
+	tokens   []token
+	pos      int
+	refs     []columnSpan // 已解析的列引用，表达式通过区间取出自己引用的列
+	opaque   []int        // 子查询的位置
+	problems []string
+}
+
+// clauseKeywords 不能作为列名或省略 AS 的别名的关键字
+var clauseKeywords = map[string]bool{
+	"FROM": true, "WHERE": true, "GROUP": true, "HAVING": true, "ORDER": true, "LIMIT": true,
+	"OFFSET": true, "FETCH": true, "UNION": true, "INTERSECT": true, "EXCEPT": true, "JOIN": true,
+	"INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "NATURAL": true,
+	"ON": true, "USING": true, "AND": true, "OR": true, "NOT": true, "AS": true, "FOR": true,
+	"INTO": true, "WINDOW": true, "OUTER": true, "WITH": true,
+}
+
+// parseSelect 解析单条 SELECT 语句
+func parseSelect(sql string, d Dialect) (*parsedQuery, error) {
+	tokens, err := tokenize(sql, d, false)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{sql: sql, tokens: tokens}
+	query, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+	query.problems = append(query.problems, p.problems...)
+	return query, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// peekAt 返回当前位置之后第 n 个词法单元
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept 依次匹配若干关键字或符号，全部匹配时才前进
+func (p *parser) accept(words ...string) bool {
+	for i, word := range words {
+		if !p.peekAt(i).is(word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return p.errorf("缺少 %s", strings.Join(words, " "))
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+	near := "语句末尾"
+	if t.kind != tokEOF {
+		near = fmt.Sprintf("%q", p.sql[t.pos:t.end])
+	}
+	return fmt.Errorf("%s（位置 %d，%s 附近）", fmt.Sprintf(format, args...), t.pos, near)
+}
+
+// problem 记录无法在画布上表示的部分
+func (p *parser) problem(format string, args ...any) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+// text 返回原文中的片段
+func (p *parser) text(start, end int) string {
+	return strings.TrimSpace(p.sql[start:end])
+}
+
+// lastEnd 返回上一个词法单元的结束位置
+func (p *parser) lastEnd() int {
+	if p.pos == 0 {
+		return 0
+	}
+	return p.tokens[p.pos-1].end
+}
+
+// skipParens 跳过一对括号及其中的内容，当前位置应为左括号
+func (p *parser) skipParens() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return p.errorf("括号未闭合")
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) query() (*parsedQuery, error) {
+	if p.peek().is("WITH") {
+		return nil, p.errorf("不支持 WITH 子句")
+	}
+	if err := p.expect("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &parsedQuery{}
+	if p.accept("DISTINCT") {
+		q.distinct = true
+	} else {
+		p.accept("ALL")
+	}
+	if p.accept("TOP") {
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		q.limit = n
+		if p.accept("PERCENT") {
+			p.problem("TOP ... PERCENT")
+		}
+	}
+
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.items = append(q.items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	var err error
+	if q.from, err = p.table(); err != nil {
+		return nil, err
+	}
+	if q.joins, err = p.joins(); err != nil {
+		return nil, err
+	}
+
+	if p.accept("WHERE") {
+		if q.where, err = p.condition(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("GROUP", "BY") {
+		for {
+			expr, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			q.groupBy = append(q.groupBy, expr)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if p.accept("WITH", "ROLLUP") {
+			p.problem("WITH ROLLUP")
+		}
+	}
+	if p.accept("HAVING") {
+		if q.having, err = p.condition(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("ORDER", "BY") {
+		if q.orderBy, err = p.orderBy(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.paging(q); err != nil {
+		return nil, err
+	}
+
+	for _, word := range []string{"UNION", "INTERSECT", "EXCEPT"} {
+		if p.peek().is(word) {
+			p.problem("%s 及之后的查询", p.text(p.peek().pos, len(p.sql)))
+			return q, nil
+		}
+	}
+	if p.accept("FOR") {
+		start := p.tokens[p.pos-1].pos
+		for p.peek().kind != tokEOF && !p.peek().is(";") {
+			p.next()
+		}
+		p.problem("%s", p.text(start, p.lastEnd()))
+	}
+	p.accept(";")
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("无法识别的内容")
+	}
+	return q, nil
+}
+
+func (p *parser) selectItem() (parsedItem, error) {
+	if p.accept("*") {
+		return parsedItem{star: "*"}, nil
+	}
+	if t := p.peek(); (t.kind == tokIdent || t.kind == tokQuoted) && p.peekAt(1).is(".") && p.peekAt(2).is("*") {
+		p.pos += 3
+		return parsedItem{star: t.text}, nil
+	}
+
+	expr, err := p.expr()
+	if err != nil {
+		return parsedItem{}, err
+	}
+	item := parsedItem{expr: expr}
+	item.alias, err = p.alias()
+	return item, err
+}
+
+// alias 解析可选的 [AS] 别名
+func (p *parser) alias() (string, error) {
+	explicit := p.accept("AS")
+	t := p.peek()
+	switch {
+	case t.kind == tokQuoted || t.kind == tokString && explicit:
+		p.next()
+		return t.text, nil
+	case t.kind == tokIdent && (explicit || !p.startsClause()):
+		p.next()
+		return t.text, nil
+	case explicit:
+		return "", p.errorf("AS 之后缺少别名")
+	default:
+		return "", nil
+	}
+}
+
+// joinKeywords 可以开始 JOIN 子句的关键字，后面跟着 JOIN 或 OUTER 时才是连接
+var joinKeywords = map[string]bool{
+	"INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "CROSS": true, "NATURAL": true,
+}
+
+// startsClause 当前的关键字是否开始下一个子句，例如 t full 中的 full 是别名，
+// t full JOIN u 中的 full 开始连接
+func (p *parser) startsClause() bool {
+	word := strings.ToUpper(p.peek().text)
+	if joinKeywords[word] {
+		next := p.peekAt(1)
+		return next.is("JOIN") || next.is("OUTER") || word == "NATURAL" && next.kind == tokIdent && joinKeywords[strings.ToUpper(next.text)]
+	}
+	return clauseKeywords[word]
+}
+
+// name 解析一个标识符
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent && t.kind != tokQuoted {
+		return "", p.errorf("缺少名称")
+	}
+	p.next()
+	return t.text, nil
+}
+
+func (p *parser) table() (parsedTable, error) {
+	if p.peek().is("(") {
+		return parsedTable{}, p.errorf("不支持 FROM/JOIN 中的子查询")
+	}
+	name, err := p.name()
+	if err != nil {
+		return parsedTable{}, err
+	}
+	table := parsedTable{name: name}
+	if p.accept(".") {
+		table.schema = name
+		if table.name, err = p.name(); err != nil {
+			return parsedTable{}, err
+		}
+	}
+	if table.alias, err = p.alias(); err != nil {
+		return parsedTable{}, err
+	}
+	// SQL Server 的表提示
+	if start := p.peek().pos; p.peek().is("WITH") && p.peekAt(1).is("(") {
+		p.next()
+		if err := p.skipParens(); err != nil {
+			return parsedTable{}, err
+		}
+		p.problem("%s", p.text(start, p.lastEnd()))
+	}
+	return table, nil
+}
+
+// joins 解析 FROM 之后的 JOIN 子句和以逗号分隔的表
+func (p *parser) joins() ([]parsedJoin, error) {
+	var joins []parsedJoin
+	for {
+		if p.accept(",") {
+			table, err := p.table()
+			if err != nil {
+				return nil, err
+			}
+			joins = append(joins, parsedJoin{joinType: CrossJoin, table: table, comma: true})
+			continue
+		}
+
+		start := p.peek().pos
+		natural := p.accept("NATURAL")
+		var joinType JoinType
+		switch {
+		case p.accept("JOIN"), p.accept("INNER", "JOIN"):
+			joinType = InnerJoin
+		case p.accept("LEFT", "JOIN"), p.accept("LEFT", "OUTER", "JOIN"):
+			joinType = LeftJoin
+		case p.accept("RIGHT", "JOIN"), p.accept("RIGHT", "OUTER", "JOIN"):
+			joinType = RightJoin
+		case p.accept("FULL", "JOIN"), p.accept("FULL", "OUTER", "JOIN"):
+			joinType = FullJoin
+		case p.accept("CROSS", "JOIN"):
+			joinType = CrossJoin
+		default:
+			if natural {
+				return nil, p.errorf("NATURAL 之后缺少 JOIN")
+			}
+			return joins, nil
+		}
+
+		table, err := p.table()
+		if err != nil {
+			return nil, err
+		}
+		join := parsedJoin{joinType: joinType, table: table}
+		if natural {
+			p.problem("%s", p.text(start, p.lastEnd()))
+		}
+		switch {
+		case p.accept("ON"):
+			if join.on, err = p.condition(); err != nil {
+				return nil, err
+			}
+		case p.accept("USING"):
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			for {
+				column, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				join.using = append(join.using, column)
+				if !p.accept(",") {
+					break
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		}
+		joins = append(joins, join)
+	}
+}
+
+func (p *parser) orderBy() ([]parsedOrder, error) {
+	var items []parsedOrder
+	for {
+		// SQL Server 分页要求的占位排序
+		if p.accept("(", "SELECT", "NULL", ")") {
+			if !p.accept(",") {
+				return items, nil
+			}
+			continue
+		}
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		item := parsedOrder{expr: expr, direction: SortAsc}
+		if p.accept("DESC") {
+			item.direction = SortDesc
+		} else {
+			p.accept("ASC")
+		}
+		if p.accept("NULLS", "FIRST") || p.accept("NULLS", "LAST") {
+			p.problem("%s", p.text(expr.start, p.lastEnd()))
+		}
+		items = append(items, item)
+		if !p.accept(",") {
+			return items, nil
+		}
+	}
+}
+
+// paging 解析各数据库的分页写法：LIMIT n [OFFSET m]、LIMIT m, n、
+// OFFSET m ROWS [FETCH NEXT n ROWS ONLY] 和 FETCH FIRST n ROWS ONLY
+func (p *parser) paging(q *parsedQuery) error {
+	var err error
+	switch {
+	case p.accept("LIMIT"):
+		if q.limit, err = p.count(); err != nil {
+			return err
+		}
+		if p.accept(",") {
+			q.offset = q.limit
+			if q.limit, err = p.count(); err != nil {
+				return err
+			}
+		} else if p.accept("OFFSET") {
+			if q.offset, err = p.count(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case p.accept("OFFSET"):
+		if q.offset, err = p.count(); err != nil {
+			return err
+		}
+		if !p.accept("ROWS") {
+			p.accept("ROW")
+		}
+	}
+	if p.accept("FETCH") {
+		if !p.accept("FIRST") {
+			if err := p.expect("NEXT"); err != nil {
+				return err
+			}
+		}
+		if q.limit, err = p.count(); err != nil {
+			return err
+		}
+		if !p.accept("ROWS") {
+			if err := p.expect("ROW"); err != nil {
+				return err
+			}
+		}
+		return p.expect("ONLY")
+	}
+	return nil
+}
+
+// count 解析分页中的非负整数
+func (p *parser) count() (int, error) {
+	t := p.peek()
+	if t.kind == tokParam {
+		return 0, p.errorf("分页参数必须是数字")
+	}
+	if p.accept("(") {
+		n, err := p.count()
+		if err != nil {
+			return 0, err
+		}
+		return n, p.expect(")")
+	}
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, p.errorf("分页参数必须是非负整数")
+	}
+	p.next()
+	return n, nil
+}
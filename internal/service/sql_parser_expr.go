@@ -0,0 +1,607 @@
+package service
+
+import (
+	"strings"
+)
+
+// parsedExpr 解析出的表达式，node 为能直接放到画布上的形式：
+// ColumnExpr、AggregateExpr、LiteralExpr、ParamExpr，其余表达式为 RawExpr
+type parsedExpr struct {
+	node     Expr
+	start    int
+	end      int
+	fn       string        // 函数调用的函数名（大写）
+	args     []*parsedExpr // 函数参数
+	refs     []columnSpan  // 表达式中引用的列
+	null     bool          // NULL 字面量
+	countAll bool          // COUNT(*)
+	opaque   bool          // 包含子查询等无法还原为计算列的内容
+}
+
+// columnSpan 列引用及其在原文中的位置
+type columnSpan struct {
+	ColumnExpr
+	start int
+	end   int
+}
+
+// parsedCond 解析出的条件：logic 非空时为 AND/OR 组合，否则为单个比较
+type parsedCond struct {
+	logic   LogicOperator
+	items   []*parsedCond
+	left    *parsedExpr
+	op      FilterOperator
+	right   []*parsedExpr
+	start   int
+	end     int
+	problem string // 无法表示为过滤条件的原因
+}
+
+// comparisonOperators 比较运算符的写法
+var comparisonOperators = map[string]FilterOperator{
+	"=": OpEqual, "<>": OpNotEqual, "!=": OpNotEqual,
+	"<": OpLess, "<=": OpLessEqual, ">": OpGreater, ">=": OpGreaterEqual,
+}
+
+// caseKeywords CASE 表达式中分隔条件的关键字
+var caseKeywords = map[string]bool{"WHEN": true, "THEN": true, "ELSE": true, "END": true}
+
+// niladicFunctions 不带括号调用的函数
+var niladicFunctions = map[string]bool{
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+	"LOCALTIME": true, "LOCALTIMESTAMP": true, "SYSDATE": true, "CURRENT_USER": true,
+}
+
+// expr 解析表达式，二元运算只保留原文
+func (p *parser) expr() (*parsedExpr, error) {
+	start, refStart := p.peek().pos, len(p.refs)
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.binaryOperator() {
+		return left, nil
+	}
+	for p.binaryOperator() {
+		p.next()
+		if _, err := p.unary(); err != nil {
+			return nil, err
+		}
+	}
+	return p.raw(start, refStart), nil
+}
+
+func (p *parser) binaryOperator() bool {
+	t := p.peek()
+	if t.kind != tokSymbol {
+		return false
+	}
+	switch t.text {
+	case "+", "-", "*", "/", "%", "||":
+		return true
+	}
+	return false
+}
+
+// raw 以原文构建从 start 到当前位置的表达式
+func (p *parser) raw(start, refStart int) *parsedExpr {
+	end := p.lastEnd()
+	e := &parsedExpr{
+		node:  RawExpr{SQL: p.text(start, end)},
+		start: start,
+		end:   end,
+		refs:  append([]columnSpan(nil), p.refs[refStart:]...),
+	}
+	for _, pos := range p.opaque {
+		if pos >= start && pos < end {
+			e.opaque = true
+		}
+	}
+	return e
+}
+
+func (p *parser) unary() (*parsedExpr, error) {
+	start, refStart := p.peek().pos, len(p.refs)
+	if !p.peek().is("-") && !p.peek().is("+") {
+		return p.primary()
+	}
+	sign := p.next()
+	if t := p.peek(); t.kind == tokNumber {
+		p.next()
+		value := t.text
+		if sign.text == "-" {
+			value = "-" + value
+		}
+		return &parsedExpr{node: LiteralExpr{Value: value, DataType: "decimal"}, start: start, end: t.end}, nil
+	}
+	if _, err := p.primary(); err != nil {
+		return nil, err
+	}
+	return p.raw(start, refStart), nil
+}
+
+func (p *parser) primary() (*parsedExpr, error) {
+	start, refStart := p.peek().pos, len(p.refs)
+	t := p.peek()
+	var e *parsedExpr
+	var err error
+	switch {
+	case t.kind == tokNumber:
+		p.next()
+		e = &parsedExpr{node: LiteralExpr{Value: t.text, DataType: "decimal"}}
+	case t.kind == tokString:
+		p.next()
+		e = &parsedExpr{node: LiteralExpr{Value: t.text}}
+	case t.kind == tokParam:
+		p.next()
+		e = &parsedExpr{node: ParamExpr{}}
+	case t.is("NULL"):
+		p.next()
+		e = &parsedExpr{node: RawExpr{SQL: "NULL"}, null: true}
+	case t.is("TRUE"), t.is("FALSE"):
+		p.next()
+		e = &parsedExpr{node: LiteralExpr{Value: strings.ToLower(t.text), DataType: "boolean"}}
+	case t.is("("):
+		if e, err = p.parenExpr(); err != nil {
+			return nil, err
+		}
+	case t.is("CASE"):
+		if err := p.caseExpr(); err != nil {
+			return nil, err
+		}
+		e = p.raw(start, refStart)
+	case t.is("INTERVAL"):
+		p.next()
+		if _, err := p.unary(); err != nil {
+			return nil, err
+		}
+		if _, err := p.name(); err != nil {
+			return nil, err
+		}
+		e = p.raw(start, refStart)
+	case t.kind == tokIdent && p.peekAt(1).is("("):
+		if e, err = p.call(); err != nil {
+			return nil, err
+		}
+	case t.kind == tokIdent && niladicFunctions[strings.ToUpper(t.text)]:
+		p.next()
+		e = p.raw(start, refStart)
+	// 关键字后跟 . 时是以关键字为别名的列引用，例如 full.id
+	case t.kind == tokIdent && clauseKeywords[strings.ToUpper(t.text)] && !p.peekAt(1).is("."),
+		t.kind != tokIdent && t.kind != tokQuoted:
+		return nil, p.errorf("缺少表达式")
+	default:
+		if e, err = p.columnRef(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 类型转换和排序规则只保留原文
+	for postfix := false; ; postfix = true {
+		switch {
+		case p.accept("::"):
+			err = p.typeName(false)
+		case p.accept("COLLATE"):
+			_, err = p.name()
+		default:
+			if postfix {
+				e = p.raw(start, refStart)
+			}
+			e.start, e.end = start, p.lastEnd()
+			return e, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parenExpr 解析括号中的表达式，子查询只记录位置
+func (p *parser) parenExpr() (*parsedExpr, error) {
+	start, refStart := p.peek().pos, len(p.refs)
+	if p.peekAt(1).is("SELECT") {
+		p.opaque = append(p.opaque, start)
+		if err := p.skipParens(); err != nil {
+			return nil, err
+		}
+		return p.raw(start, refStart), nil
+	}
+	p.next()
+	inner, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if _, ok := inner.node.(ColumnExpr); ok {
+		return inner, nil
+	}
+	return p.raw(start, refStart), nil
+}
+
+// columnRef 解析 [库名.][表名.]列名
+func (p *parser) columnRef() (*parsedExpr, error) {
+	start := p.peek().pos
+	parts := []string{}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, name)
+		if !p.peek().is(".") || p.peekAt(1).is("*") {
+			break
+		}
+		p.next()
+	}
+	column := ColumnExpr{Column: parts[len(parts)-1]}
+	if len(parts) > 1 {
+		column.Qualifier = parts[len(parts)-2]
+	}
+	p.refs = append(p.refs, columnSpan{ColumnExpr: column, start: start, end: p.lastEnd()})
+	return &parsedExpr{node: column}, nil
+}
+
+// typeName 解析类型名，multiword 为 true 时允许 DOUBLE PRECISION 之类的多个单词
+func (p *parser) typeName(multiword bool) error {
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	for multiword && p.peek().kind == tokIdent {
+		p.next()
+	}
+	if p.peek().is("(") {
+		return p.skipParens()
+	}
+	return nil
+}
+
+// caseExpr 解析 CASE 表达式，只需要确定其范围和引用的列
+func (p *parser) caseExpr() error {
+	p.next()
+	simple := !p.peek().is("WHEN")
+	if simple {
+		if _, err := p.expr(); err != nil {
+			return err
+		}
+	}
+	if !p.peek().is("WHEN") {
+		return p.errorf("CASE 缺少 WHEN")
+	}
+	for p.accept("WHEN") {
+		var err error
+		if simple {
+			_, err = p.expr()
+		} else {
+			_, err = p.condition()
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.expect("THEN"); err != nil {
+			return err
+		}
+		if _, err := p.expr(); err != nil {
+			return err
+		}
+	}
+	if p.accept("ELSE") {
+		if _, err := p.expr(); err != nil {
+			return err
+		}
+	}
+	return p.expect("END")
+}
+
+// call 解析函数调用，能对应到画布聚合方式的聚合函数转换为 AggregateExpr
+func (p *parser) call() (*parsedExpr, error) {
+	start, refStart := p.peek().pos, len(p.refs)
+	name := strings.ToUpper(p.next().text)
+	p.next()
+
+	star := false
+	extra := false // 参数中有分隔符、排序等画布无法表示的部分
+	distinct := p.accept("DISTINCT")
+	if !distinct {
+		p.accept("ALL")
+	}
+	var args []*parsedExpr
+	switch {
+	case p.accept("*"):
+		star = true
+	case p.peek().is(")"):
+	default:
+		for {
+			// EXTRACT(YEAR FROM x)、TRIM(BOTH 'x' FROM y) 中的关键字
+			if t := p.peek(); t.kind == tokIdent && (name == "EXTRACT" && len(args) == 0 ||
+				t.is("BOTH") || t.is("LEADING") || t.is("TRAILING")) {
+				p.next()
+				extra = true
+			}
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			for more := true; more; {
+				switch {
+				case p.accept("AS"):
+					err = p.typeName(true)
+				case p.accept("FROM"), p.accept("FOR"), p.accept("USING"), p.accept("SEPARATOR"):
+					_, err = p.expr()
+					extra = true
+				case p.accept("ORDER", "BY"):
+					_, err = p.orderBy()
+					extra = true
+				default:
+					more = false
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	withinGroup := false
+	if p.accept("WITHIN", "GROUP") {
+		if err := p.skipParens(); err != nil {
+			return nil, err
+		}
+		withinGroup = true
+	}
+	window := false
+	if p.accept("FILTER") || p.accept("OVER") {
+		if p.peek().is("(") {
+			if err := p.skipParens(); err != nil {
+				return nil, err
+			}
+		} else if _, err := p.name(); err != nil {
+			return nil, err
+		}
+		window = true
+	}
+
+	e := p.raw(start, refStart)
+	e.fn = name
+	e.args = args
+	e.countAll = star && name == "COUNT" && !distinct && !window && !withinGroup
+	if star || window || extra {
+		return e, nil
+	}
+	if agg, arg := aggregateCall(name, distinct, withinGroup, args); agg != AggNone {
+		e.node = AggregateExpr{Func: agg, Arg: arg.node}
+		e.args = []*parsedExpr{arg}
+	}
+	return e, nil
+}
+
+// aggregateCall 识别画布支持的聚合函数，包括各方言中字符串拼接聚合的写法
+func aggregateCall(name string, distinct, withinGroup bool, args []*parsedExpr) (AggregateFunc, *parsedExpr) {
+	separatorIsComma := func() bool {
+		lit, ok := args[1].node.(LiteralExpr)
+		return ok && lit.Value == ","
+	}
+	switch {
+	case name == "COUNT" && len(args) == 1 && distinct:
+		return AggCountDistinct, args[0]
+	case distinct:
+		return AggNone, nil
+	case len(args) == 1 && !withinGroup:
+		switch name {
+		case "COUNT", "SUM", "AVG", "MIN", "MAX", "GROUP_CONCAT":
+			return AggregateFunc(name), args[0]
+		}
+	case len(args) == 2 && separatorIsComma() && (name == "STRING_AGG" && !withinGroup || name == "LISTAGG"):
+		return AggGroupConcat, uncast(args[0])
+	}
+	return AggNone, nil
+}
+
+// uncast 去掉生成语句时为拼接聚合加上的类型转换
+func uncast(e *parsedExpr) *parsedExpr {
+	if e.fn == "CAST" && len(e.args) == 1 {
+		return e.args[0]
+	}
+	return e
+}
+
+// condition 解析以 OR 组合的条件
+func (p *parser) condition() (*parsedCond, error) {
+	return p.logical(LogicOr, p.conjunction)
+}
+
+// conjunction 解析以 AND 组合的条件
+func (p *parser) conjunction() (*parsedCond, error) {
+	return p.logical(LogicAnd, p.negation)
+}
+
+func (p *parser) logical(op LogicOperator, operand func() (*parsedCond, error)) (*parsedCond, error) {
+	start := p.peek().pos
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	items := []*parsedCond{first}
+	for p.accept(string(op)) {
+		item, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return &parsedCond{logic: op, items: items, start: start, end: p.lastEnd()}, nil
+}
+
+func (p *parser) negation() (*parsedCond, error) {
+	start := p.peek().pos
+	if !p.accept("NOT") {
+		return p.predicate()
+	}
+	if _, err := p.negation(); err != nil {
+		return nil, err
+	}
+	return p.unsupported(start, "NOT 条件"), nil
+}
+
+// unsupported 返回从 start 到当前位置的无法导入的条件
+func (p *parser) unsupported(start int, reason string) *parsedCond {
+	return &parsedCond{start: start, end: p.lastEnd(), problem: reason}
+}
+
+func (p *parser) predicate() (*parsedCond, error) {
+	start := p.peek().pos
+	if p.accept("EXISTS") {
+		if err := p.skipParens(); err != nil {
+			return nil, err
+		}
+		return p.unsupported(start, "EXISTS 子查询"), nil
+	}
+
+	// 括号中可能是条件组，也可能是表达式的一部分，先按条件组尝试
+	if p.peek().is("(") && !p.peekAt(1).is("SELECT") {
+		pos, refs, opaque := p.pos, len(p.refs), len(p.opaque)
+		p.next()
+		if cond, err := p.condition(); err == nil && p.accept(")") && !p.continuesExpr() {
+			return cond, nil
+		}
+		p.pos, p.refs, p.opaque = pos, p.refs[:refs], p.opaque[:opaque]
+	}
+
+	left, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	cond := &parsedCond{left: left, start: start}
+	not := p.accept("NOT")
+	switch {
+	case !not && p.accept("IS"):
+		negate := p.accept("NOT")
+		if !p.accept("NULL") {
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			return p.unsupported(start, "IS 条件"), nil
+		}
+		cond.op = OpIsNull
+		if negate {
+			cond.op = OpIsNotNull
+		}
+	case p.accept("BETWEEN"):
+		lower, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		upper, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if not {
+			return p.unsupported(start, "NOT BETWEEN"), nil
+		}
+		cond.op, cond.right = OpBetween, []*parsedExpr{lower, upper}
+	case p.accept("IN"):
+		if p.peek().is("(") && p.peekAt(1).is("SELECT") {
+			if err := p.skipParens(); err != nil {
+				return nil, err
+			}
+			return p.unsupported(start, "IN 子查询"), nil
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			cond.right = append(cond.right, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		cond.op = negated(OpIn, OpNotIn, not)
+	case p.accept("LIKE"), p.accept("ILIKE"):
+		op := negated(OpLike, OpNotLike, not)
+		if p.tokens[p.pos-1].is("ILIKE") {
+			op = negated(OpILike, OpNotILike, not)
+		}
+		pattern, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept("ESCAPE") {
+			if _, err := p.expr(); err != nil {
+				return nil, err
+			}
+			return p.unsupported(start, "LIKE ... ESCAPE"), nil
+		}
+		cond.op, cond.right = op, []*parsedExpr{pattern}
+	case !not && p.peek().kind == tokSymbol && comparisonOperators[p.peek().text] != "":
+		cond.op = comparisonOperators[p.next().text]
+		if p.accept("ANY") || p.accept("ALL") || p.accept("SOME") {
+			if err := p.skipParens(); err != nil {
+				return nil, err
+			}
+			return p.unsupported(start, "ANY/ALL 子查询"), nil
+		}
+		right, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		cond.right = []*parsedExpr{right}
+	case p.peek().kind == tokIdent && !clauseKeywords[strings.ToUpper(p.peek().text)] && !caseKeywords[strings.ToUpper(p.peek().text)]:
+		// REGEXP、SIMILAR TO 等画布不支持的运算符
+		for p.peek().kind == tokIdent {
+			p.next()
+		}
+		if _, err := p.expr(); err != nil {
+			return nil, err
+		}
+		return p.unsupported(start, "不支持的运算符"), nil
+	default:
+		if not {
+			return nil, p.errorf("NOT 之后缺少运算符")
+		}
+		return p.unsupported(start, "不是比较条件"), nil
+	}
+	cond.end = p.lastEnd()
+	return cond, nil
+}
+
+// continuesExpr 当前位置是否为表达式或比较的延续，用于判断括号是否属于表达式
+func (p *parser) continuesExpr() bool {
+	t := p.peek()
+	if p.binaryOperator() || t.kind == tokSymbol && comparisonOperators[t.text] != "" {
+		return true
+	}
+	for _, word := range []string{"IS", "IN", "NOT", "LIKE", "ILIKE", "BETWEEN"} {
+		if t.is(word) {
+			return true
+		}
+	}
+	return false
+}
+
+func negated(op, negatedOp FilterOperator, not bool) FilterOperator {
+	if not {
+		return negatedOp
+	}
+	return op
+}
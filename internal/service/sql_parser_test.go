@@ -0,0 +1,51 @@
+package service
+
+import "testing"
+
+// 非保留关键字可以作为别名，省略 AS 时只有开始下一个子句的关键字不作为别名
+func TestParseKeywordAliases(t *testing.T) {
+	tests := []struct {
+		sql   string
+		from  string
+		joins []string
+	}{
+		{"SELECT * FROM t AS full", "full", nil},
+		{"SELECT * FROM t full", "full", nil},
+		{"SELECT * FROM t full WHERE full.id = 1", "full", nil},
+		{"SELECT * FROM t AS left", "left", nil},
+		{"SELECT * FROM t AS where", "where", nil},
+		{"SELECT * FROM t full JOIN u ON t.id = u.id", "", []string{""}},
+		{"SELECT * FROM t left LEFT JOIN u right ON left.id = right.id", "left", []string{"right"}},
+		{"SELECT * FROM t LEFT OUTER JOIN u full ON t.id = full.id", "", []string{"full"}},
+		{"SELECT * FROM t cross CROSS JOIN u", "cross", []string{""}},
+	}
+	for _, tt := range tests {
+		q, err := parseSelect(tt.sql, MySQL)
+		if err != nil {
+			t.Errorf("parseSelect(%q): %v", tt.sql, err)
+			continue
+		}
+		if q.from.alias != tt.from {
+			t.Errorf("parseSelect(%q) FROM alias = %q, want %q", tt.sql, q.from.alias, tt.from)
+		}
+		if len(q.joins) != len(tt.joins) {
+			t.Errorf("parseSelect(%q) has %d joins, want %d", tt.sql, len(q.joins), len(tt.joins))
+			continue
+		}
+		for i, join := range q.joins {
+			if join.table.alias != tt.joins[i] {
+				t.Errorf("parseSelect(%q) join %d alias = %q, want %q", tt.sql, i, join.table.alias, tt.joins[i])
+			}
+		}
+	}
+
+	items, err := parseSelect("SELECT a full, b AS from FROM t", MySQL)
+	if err != nil {
+		t.Fatalf("parseSelect: %v", err)
+	}
+	for i, want := range []string{"full", "from"} {
+		if got := items.items[i].alias; got != want {
+			t.Errorf("column %d alias = %q, want %q", i, got, want)
+		}
+	}
+}
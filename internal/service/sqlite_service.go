@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lowSqlGen/internal/model"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteService SQLite 数据库服务，以只读方式打开本地数据库文件。
+// 界面中的“数据库”对应 main 以及附加的数据库，SQLite 没有表和列注释
+type sqliteService struct {
+	db     *sql.DB
+	config *model.DatabaseConfig
+}
+
+// sqliteColumnFields 列信息字段，顺序与 scanColumn 一致。
+// p 为 pragma_table_info 的结果，%[1]s 为表名表达式，?1 为数据库名
+const sqliteColumnFields = `
+	p.name,
+	p.type,
+	CASE WHEN p."notnull" THEN 'NO' ELSE 'YES' END,
+	CASE
+		WHEN p.pk > 0 THEN 'PRI'
+		WHEN EXISTS (SELECT 1 FROM pragma_index_list(%[1]s, ?1) il
+			JOIN pragma_index_info(il.name, ?1) ii
+			WHERE il."unique" AND ii.seqno = 0 AND ii.name = p.name
+				AND (SELECT COUNT(*) FROM pragma_index_info(il.name, ?1)) = 1) THEN 'UNI'
+		WHEN EXISTS (SELECT 1 FROM pragma_index_list(%[1]s, ?1) il
+			JOIN pragma_index_info(il.name, ?1) ii
+			WHERE ii.seqno = 0 AND ii.name = p.name) THEN 'MUL'
+		ELSE ''
+	END,
+	p.dflt_value,
+	'',
+	'',
+	NULL`
+
+func newSQLiteService(config *model.DatabaseConfig) (DatabaseService, error) {
+	// 只读打开，避免文件不存在时创建空数据库
+	if _, err := os.Stat(config.FilePath); err != nil {
+		return nil, fmt.Errorf("打开数据库文件失败: %v", err)
+	}
+
+	db, err := openDB("sqlite3", "file:"+config.FilePath+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteService{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// GetDatabases 获取 main 及附加的数据库，临时数据库不列出
+func (s *sqliteService) GetDatabases() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM pragma_database_list WHERE name <> 'temp' ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+func (s *sqliteService) GetTables(dbName string) ([]string, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT name FROM %s.sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite\_%%' ESCAPE '\'
+		ORDER BY name
+	`, quoteSQLite(dbName)))
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+// GetColumns 按定义顺序获取列，键信息来自主键和索引
+func (s *sqliteService) GetColumns(dbName, tableName string) ([]model.Column, error) {
+	query := `SELECT ` + fmt.Sprintf(sqliteColumnFields, "?2") + `
+		FROM pragma_table_info(?2, ?1) p
+		ORDER BY p.cid
+	`
+	rows, err := s.db.Query(query, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanColumns(rows)
+}
+
+func (s *sqliteService) Close() error {
+	return s.db.Close()
+}
+
+// GetTableComment SQLite 不支持表注释
+func (s *sqliteService) GetTableComment(dbName, tableName string) string {
+	return ""
+}
+
+// GetForeignKeys 获取引用该表或被该表引用的外键，
+// 未写被引用列时外键指向被引用表的主键
+func (s *sqliteService) GetForeignKeys(dbName, tableName string) ([]model.ForeignKey, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT m.name || '#' || fk.id, m.name, fk."from", fk."table",
+			COALESCE(fk."to", (SELECT p.name FROM pragma_table_info(fk."table", ?1) p WHERE p.pk = fk.seq + 1))
+		FROM %s.sqlite_master m
+		JOIN pragma_foreign_key_list(m.name, ?1) fk
+		WHERE m.type = 'table' AND (m.name = ?2 OR fk."table" = ?2)
+		ORDER BY m.name, fk.id, fk.seq
+	`, quoteSQLite(dbName)), dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(rows)
+}
+
+// GetIndexes 获取表的索引。INTEGER PRIMARY KEY 是 rowid 的别名，
+// 不出现在 pragma_index_list 中，按 pragma_table_info 补上主键
+func (s *sqliteService) GetIndexes(dbName, tableName string) ([]model.Index, error) {
+	rows, err := s.db.Query(`
+		SELECT name, ?2, col, uniq, pri FROM (
+			SELECT il.name AS name, ii.name AS col, il."unique" AS uniq, il.origin = 'pk' AS pri, ii.seqno AS seq
+			FROM pragma_index_list(?2, ?1) il
+			JOIN pragma_index_info(il.name, ?1) ii
+			UNION ALL
+			SELECT 'PRIMARY', p.name, 1, 1, p.pk
+			FROM pragma_table_info(?2, ?1) p
+			WHERE p.pk > 0 AND NOT EXISTS (SELECT 1 FROM pragma_index_list(?2, ?1) WHERE origin = 'pk')
+		)
+		ORDER BY name, seq
+	`, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanIndexes(rows)
+}
+
+// GetObjects 获取表、视图和触发器，SQLite 没有存储过程和函数
+func (s *sqliteService) GetObjects(dbName string) ([]model.SchemaObject, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT name, type, CASE WHEN type = 'trigger' THEN tbl_name ELSE '' END
+		FROM %s.sqlite_master
+		WHERE type IN ('table', 'view', 'trigger') AND name NOT LIKE 'sqlite\_%%' ESCAPE '\'
+		ORDER BY type, name
+	`, quoteSQLite(dbName)))
+	if err != nil {
+		return nil, err
+	}
+	return scanObjects(rows)
+}
+
+// GetDefinition 获取 sqlite_master 中保存的创建语句
+func (s *sqliteService) GetDefinition(dbName string, object model.SchemaObject) (string, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT sql FROM %s.sqlite_master WHERE type = ? AND name = ?`,
+		quoteSQLite(dbName)), object.Type, object.Name)
+	if err != nil {
+		return "", err
+	}
+	return scanDefinition(rows, 0, object.Name)
+}
+
+// LoadSchema 一次查询加载数据库中所有表和视图的列信息
+func (s *sqliteService) LoadSchema(dbName string) (*model.Schema, error) {
+	query := fmt.Sprintf(`SELECT m.name, '', `+fmt.Sprintf(sqliteColumnFields, "m.name")+`
+		FROM %s.sqlite_master m
+		JOIN pragma_table_info(m.name, ?1) p
+		WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite\_%%' ESCAPE '\'
+		ORDER BY m.name, p.cid
+	`, quoteSQLite(dbName))
+	rows, err := s.db.Query(query, dbName)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchema(dbName, rows)
+}
+
+// Query 执行查询，未限定的表名按 SQLite 的规则先在 main 中查找
+func (s *sqliteService) Query(ctx context.Context, dbName, query string) (*RowIterator, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return queryRows(ctx, conn, query)
+}
+
+// quoteSQLite 引用数据库名，用于不能绑定参数的位置
+func quoteSQLite(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+)
+
+// TableInstance 查询中的一个表实例，同一张表可以出现多次（自连接）
+type TableInstance struct {
+	ID    string `json:"id"`              // 实例ID，在查询中唯一
+	Table string `json:"table"`           // 数据库中的表名
+	Alias string `json:"alias,omitempty"` // 自定义别名，为空时自动生成 t1、t2...
+	// Database 表所在的数据库，为空时使用当前数据库
+	Database string `json:"database,omitempty"`
+}
+
+// instance 根据实例ID查找已登记的表实例
+func (g *SQLGenerator) instance(id string) (TableInstance, bool) {
+	for _, inst := range g.tables {
+		if inst.ID == id {
+			return inst, true
+		}
+	}
+	return TableInstance{}, false
+}
+
+// tableName 返回实例对应的数据库表名
+func (g *SQLGenerator) tableName(id string) string {
+	if inst, ok := g.instance(id); ok && inst.Table != "" {
+		return inst.Table
+	}
+	return id
+}
+
+// instanceIDs 按登记顺序返回所有实例ID
+func (g *SQLGenerator) instanceIDs() []string {
+	ids := make([]string, len(g.tables))
+	for i, inst := range g.tables {
+		ids[i] = inst.ID
+	}
+	return ids
+}
+
+// assignAliases 优先使用自定义别名，其余实例按主表为 t1、其他按登记顺序编号
+func (g *SQLGenerator) assignAliases() error {
+	g.tableAliases = make(map[string]string)
+	used := make(map[string]string) // 别名 -> 实例ID
+	for _, inst := range g.tables {
+		if inst.Alias == "" {
+			continue
+		}
+		if err := ValidateAlias(inst.Alias); err != nil {
+			return err
+		}
+		if owner, exists := used[inst.Alias]; exists {
+			return fmt.Errorf("表别名 %s 同时用于 %s 和 %s", inst.Alias, owner, inst.ID)
+		}
+		used[inst.Alias] = inst.ID
+		g.tableAliases[inst.ID] = inst.Alias
+	}
+
+	next := 1
+	generate := func(id string) {
+		if _, exists := g.tableAliases[id]; exists {
+			return
+		}
+		for {
+			alias := fmt.Sprintf("t%d", next)
+			next++
+			if _, taken := used[alias]; !taken {
+				used[alias] = id
+				g.tableAliases[id] = alias
+				return
+			}
+		}
+	}
+	generate(g.mainTable)
+	for _, inst := range g.tables {
+		generate(inst.ID)
+	}
+	return nil
+}